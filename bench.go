@@ -0,0 +1,74 @@
+package trial
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// Bench runs each case as a sub-benchmark that calls the test function b.N times
+// and reports allocations.
+// The output of each case is checked once before timing so the benchmark
+// doesn't measure a broken function, see SkipValidation.
+// Cases that should panic are skipped.
+// BeforeEach is called before timing starts and AfterEach after it stops
+// (as well as around the validation), the result given to AfterEach after timing
+// only has the output and error of the last call.
+//
+//	func BenchmarkParse(b *testing.B) {
+//		trial.New(parse, cases).Bench(b)
+//	}
+func (t *Trial[In, Out]) Bench(b *testing.B) {
+	b.Helper()
+	t.start(b)
+	if t.afterAll != nil {
+		b.Cleanup(t.afterAll)
+	}
+	for _, msg := range t.names() {
+		msg, test := msg, t.cases[msg]
+		// the benchmark function is called multiple times to find b.N
+		var once sync.Once
+		var failure string
+		b.Run(msg, func(b *testing.B) {
+			b.Helper()
+			if reason := t.skipReason(test); reason != "" {
+				b.Skip(reason)
+			}
+			if test.ShouldPanic || test.ExpectedPanic != nil {
+				b.Skip("expects a panic")
+			}
+			if !t.skipValidation {
+				once.Do(func() {
+					if r := t.testCase(msg, test); !r.Success {
+						failure = t.format(r.status(), subMessage(msg, r.Message))
+					}
+				})
+				if failure != "" {
+					b.Fatal(failure)
+				}
+			}
+			in := test.Input
+			if t.beforeEach != nil {
+				t.beforeEach(msg, &in)
+			}
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			var out Out
+			var err error
+			for i := 0; i < b.N; i++ {
+				out, err = t.testFn(ctx, in)
+			}
+			b.StopTimer()
+			if t.afterEach != nil {
+				t.afterEach(msg, Result[Out]{Name: msg, Input: in, Output: out, Err: err})
+			}
+		})
+	}
+}
+
+// SkipValidation benchmarks the cases without checking their output first
+func (t *Trial[In, Out]) SkipValidation() *Trial[In, Out] {
+	t.skipValidation = true
+	return t
+}
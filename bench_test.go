@@ -0,0 +1,63 @@
+package trial
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func BenchmarkTrial(b *testing.B) {
+	fn := func(s string) ([]string, error) {
+		return strings.Split(s, ","), nil
+	}
+	cases := Cases[string, []string]{
+		"one":   {Input: "a", Expected: []string{"a"}},
+		"three": {Input: "a,b,c", Expected: []string{"a", "b", "c"}},
+		"later": {Input: "a,b", Skip: "not ready"},
+	}
+	New(fn, cases).SubTest(b)
+}
+
+func TestTrial_Bench(t *testing.T) {
+	// keep the benchmarks short
+	bt := flag.Lookup("test.benchtime")
+	prev := bt.Value.String()
+	bt.Value.Set("10x")
+	defer bt.Value.Set(prev)
+
+	fn := func(skip bool) (map[string]int, error) {
+		calls := make(map[string]int)
+		count := func(s string) (string, error) {
+			calls[s]++
+			if s == "panic" {
+				panic(s)
+			}
+			return s, nil
+		}
+		cases := Cases[string, string]{
+			"ok":    {Input: "ok", Expected: "ok"},
+			"wrong": {Input: "wrong", Expected: "right"},
+			"panic": {Input: "panic", ShouldPanic: true},
+		}
+		tr := New(count, cases).AfterEach(func(_ string, r Result[string]) {
+			calls["after "+r.Output]++
+		})
+		if skip {
+			tr.SkipValidation()
+		}
+		testing.Benchmark(tr.Bench)
+		return calls, nil
+	}
+	// benchmarks run once with b.N = 1 and then with the benchtime of 10
+	cases := Cases[bool, map[string]int]{
+		"validate": {
+			Input:    false,
+			Expected: map[string]int{"ok": 12, "wrong": 1, "after ok": 3, "after wrong": 1},
+		},
+		"skip validation": {
+			Input:    true,
+			Expected: map[string]int{"ok": 11, "wrong": 11, "after ok": 2, "after wrong": 2},
+		},
+	}
+	New(fn, cases).SubTest(t)
+}
@@ -0,0 +1,91 @@
+package trial
+
+import (
+	"os"
+)
+
+// Status of a case after it has run
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusSkip    Status = "skip"
+	StatusPanic   Status = "panic"
+	StatusTimeout Status = "timeout"
+)
+
+// Failed is true for any status that fails the test
+func (s Status) Failed() bool {
+	return s == StatusFail || s == StatusPanic || s == StatusTimeout
+}
+
+// Formatter controls how the PASS, FAIL, PANIC and SKIP messages
+// are rendered when logged by Test and SubTest
+type Formatter interface {
+	Format(status Status, msg string) string
+}
+
+// ColorMode determines if messages are colored
+type ColorMode int
+
+const (
+	ColorAuto ColorMode = iota // color when writing to a terminal (see NO_COLOR and TERM)
+	ColorOn
+	ColorOff
+)
+
+// Color sets when failure messages are colored. default ColorAuto
+func (t *Trial[In, Out]) Color(c ColorMode) *Trial[In, Out] {
+	t.color = c
+	return t
+}
+
+// Formatter overrides how messages are rendered.
+// The color mode is ignored when a custom formatter is used.
+func (t *Trial[In, Out]) Formatter(f Formatter) *Trial[In, Out] {
+	t.formatter = f
+	return t
+}
+
+// format the message with the trial's formatter
+func (t *Trial[In, Out]) format(status Status, msg string) string {
+	if t.formatter != nil {
+		return t.formatter.Format(status, msg)
+	}
+	switch t.color {
+	case ColorOn:
+		return textFormat{color: true}.Format(status, msg)
+	case ColorOff:
+		return textFormat{}.Format(status, msg)
+	}
+	return textFormat{color: isTerminal()}.Format(status, msg)
+}
+
+// textFormat is the default formatter, failures are shown in red
+type textFormat struct {
+	color bool
+}
+
+func (f textFormat) Format(status Status, msg string) string {
+	if f.color && status.Failed() {
+		return "\033[31m" + msg + "\033[39m"
+	}
+	return msg
+}
+
+// isTerminal checks if colors should be used for the output.
+// see https://no-color.org
+func isTerminal() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,66 @@
+package trial
+
+import (
+	"testing"
+)
+
+type upperFormat struct{}
+
+func (upperFormat) Format(status Status, msg string) string {
+	return string(status) + "|" + msg
+}
+
+func TestTrial_Format(t *testing.T) {
+	type input struct {
+		trial   *Trial[int, int]
+		status  Status
+		noColor string
+	}
+	fn := func(in input) (string, error) {
+		t.Setenv("NO_COLOR", in.noColor)
+		t.Setenv("TERM", "xterm")
+		return in.trial.format(in.status, "msg"), nil
+	}
+	tr := func() *Trial[int, int] { return New(func(i int) (int, error) { return i, nil }, nil) }
+	cases := Cases[input, string]{
+		"color on fail": {
+			Input:    input{trial: tr().Color(ColorOn), status: StatusFail},
+			Expected: "\033[31mmsg\033[39m",
+		},
+		"color on panic": {
+			Input:    input{trial: tr().Color(ColorOn), status: StatusPanic},
+			Expected: "\033[31mmsg\033[39m",
+		},
+		"color on pass": {
+			Input:    input{trial: tr().Color(ColorOn), status: StatusPass},
+			Expected: "msg",
+		},
+		"color off": {
+			Input:    input{trial: tr().Color(ColorOff), status: StatusTimeout},
+			Expected: "msg",
+		},
+		"auto NO_COLOR": {
+			Input:    input{trial: tr(), status: StatusFail, noColor: "1"},
+			Expected: "msg",
+		},
+		"custom formatter": {
+			Input:    input{trial: tr().Color(ColorOn).Formatter(upperFormat{}), status: StatusSkip},
+			Expected: "skip|msg",
+		},
+	}
+	New(fn, cases).Test(t)
+}
+
+func TestIsTerminal(t *testing.T) {
+	fn := func(env [2]string) (bool, error) {
+		t.Setenv("NO_COLOR", env[0])
+		t.Setenv("TERM", env[1])
+		return isTerminal(), nil
+	}
+	// stdout may or may not be a terminal so only the disabled cases are checked
+	New(fn, Cases[[2]string, bool]{
+		"NO_COLOR set": {Input: [2]string{"1", "xterm"}, Expected: false},
+		"dumb term":    {Input: [2]string{"", "dumb"}, Expected: false},
+		"no term":      {Input: [2]string{"", ""}, Expected: false},
+	}).Test(t)
+}
@@ -2,49 +2,167 @@ package trial
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 // Contains determines if y is a subset of x.
+// Numbers must be equal, see ContainsOpt with FloatTolerance or DurationTolerance.
 // x is a string -> y is a string that is equal to or a subset of x (string.Contains)
 // x is a slice or array -> y is contained in x
 // x is a map -> y is a map and is contained in x
+// x is a struct -> the non-zero fields of y are contained in x (zero fields are ignored)
+// x is a pointer -> the value y points to is contained in the value x points to
 func Contains(x, y interface{}) (bool, string) {
+	return defaultContains.compare(x, y)
+}
+
+// defaultContains are the options used by Contains
+var defaultContains = newContainsCfg(SubStrings, SubSlices, SubMaps)
+
+// ContainsOption configures how ContainsOpt checks values
+type ContainsOption interface {
+	apply(*containsCfg)
+}
+
+// ContainsFlag turns on a ContainsOpt check
+type ContainsFlag int
+
+const (
+	SubStrings    ContainsFlag = 1 << iota // expected string is a substring of actual ("abc" -> "abcdefg")
+	SubSlices                              // expected slice is a subset of actual (["a"] -> ["a","b","c"])
+	SubMaps                                // expected map is a subset of actual, extra keys are allowed
+	RegexStrings                           // expected string is a regular expression matched against actual
+	OrderedSlices                          // expected slice is an ordered subsequence of actual (["a","c"] -> ["a","b","c"])
+	IgnoreCase                             // strings are compared ignoring case
+)
+
+func (f ContainsFlag) apply(c *containsCfg) {
+	c.flags |= f
+}
+
+// ContainsOpt creates a contains CompareFunc with only the checks given.
+// Without SubStrings strings must be equal, without SubSlices or OrderedSlices
+// slices must have the same length and each value is checked by index and
+// without SubMaps maps must have the same keys.
+// Values inside slices and maps are checked with the same options.
+//
+//	trial.New(fn, cases).Comparer(trial.ContainsOpt(trial.SubMaps, trial.IgnoreCase))
+func ContainsOpt(opts ...ContainsOption) CompareFunc {
+	return newContainsCfg(opts...).compare
+}
+
+type containsCfg struct {
+	flags ContainsFlag
+
+	// float tolerance (see FloatTolerance)
+	approx   bool
+	fraction float64
+	margin   float64
+
+	// duration tolerance (see DurationTolerance)
+	durApprox bool
+	duration  time.Duration
+
+	// values in slices must be equal (see Subsequence)
+	exact bool
+
+	// pointers already being compared, used to stop on cyclic values
+	visited map[ptrPair]bool
+}
+
+// exactValues compares slice values with Equal
+var exactValues = &containsCfg{exact: true}
+
+// match is true if the slice value y matches the slice value x
+func (c *containsCfg) match(x, y interface{}) bool {
+	if c.exact {
+		eq, _ := Equal(x, y)
+		return eq
+	}
+	return c.contains(x, y) == nil
+}
+
+// ptrPair is an actual and expected pointer that are compared
+type ptrPair struct {
+	typ  reflect.Type
+	x, y uintptr
+}
+
+// checker returns a copy of the options to compare a single pair of values
+func (c *containsCfg) checker() *containsCfg {
+	cc := *c
+	cc.visited = make(map[ptrPair]bool)
+	return &cc
+}
+
+// containsFunc is a ContainsOption with settings
+type containsFunc func(*containsCfg)
+
+func (f containsFunc) apply(c *containsCfg) {
+	f(c)
+}
+
+// FloatTolerance is a ContainsOpt option where floats are contained if they are within
+// the margin or fraction of each other (see ApproxFloat).
+//
+//	|x-y| ≤ max(fraction*min(|x|, |y|), margin)
+func FloatTolerance(fraction, margin float64) ContainsOption {
+	return containsFunc(func(c *containsCfg) {
+		c.approx = true
+		c.fraction = fraction
+		c.margin = margin
+	})
+}
+
+// DurationTolerance is a ContainsOpt option where time.Duration values
+// are contained if their difference is less than or equal to d (see ApproxDuration).
+func DurationTolerance(d time.Duration) ContainsOption {
+	return containsFunc(func(c *containsCfg) {
+		c.durApprox = true
+		c.duration = d
+	})
+}
+
+func newContainsCfg(opts ...ContainsOption) *containsCfg {
+	c := &containsCfg{}
+	for _, o := range opts {
+		o.apply(c)
+	}
+	return c
+}
+
+func (c *containsCfg) is(f ContainsFlag) bool {
+	return c.flags&f != 0
+}
+
+func (c *containsCfg) compare(x, y interface{}) (bool, string) {
 	// if nothing is expected we have a match
 	if y == nil {
 		return true, ""
 	}
-	r := contains(x, y)
+	r := c.checker().contains(x, y)
 	if r == nil {
 		return true, ""
 	}
 	return false, r.String()
 }
 
-const (
-	SubStrings = iota
-	SubSlices
-	SubMaps
-)
-
-// ContainsOpt allow configurable options to the contains method
-// 1. Check for sub-strings ("abc" -> "abcdefg")
-// 2. Check for sub-slices (["a"] -> ["a","b","c"])
-// 3. Check for sub-maps
-// 4. use regex match as a sub-string check
-/*
-func ContainsOpt(o interface{}) CompareFunc {
-	return Contains
-}
-*/
-
-func contains(x, y interface{}) differ {
+func (c *containsCfg) contains(x, y interface{}) differ {
+	if cmpr, ok := x.(Comparer); ok && !isNil(reflect.ValueOf(x)) {
+		if eq, d := cmpr.Equals(y); !eq {
+			return newDiffMsg(x, y, d)
+		}
+		return nil
+	}
 	valX := reflect.ValueOf(x)
 	valY := reflect.ValueOf(y)
 	switch valX.Kind() {
@@ -63,26 +181,32 @@ func contains(x, y interface{}) differ {
 				for i, v := range arr {
 					arrI[i] = v
 				}
-				return isInSlice(reflect.ValueOf(v), arrI...)
+				return c.isInSlice(reflect.ValueOf(v), arrI...)
 
 			}
 		}
-		if strings.Contains(valX.String(), s) {
-			return nil
-		}
-		return newDiff(x, s)
+		return c.isInString(x, valX.String(), s)
 	case reflect.Array, reflect.Slice:
 		if valY.Kind() == reflect.Slice || valY.Kind() == reflect.Array {
 			child := make([]interface{}, valY.Len())
 			for i := 0; i < valY.Len(); i++ {
 				child[i] = valY.Index(i).Interface()
 			}
-			if d := isInSlice(valX, child...); d != nil {
+			var d differ
+			switch {
+			case c.is(OrderedSlices):
+				d = c.isInOrder(valX, child...)
+			case c.is(SubSlices):
+				d = c.isInSlice(valX, child...)
+			default:
+				d = c.isSameSlice(valX, child...)
+			}
+			if d != nil {
 				return newDiffMsg(x, y, d.String())
 			}
 			return nil
 		}
-		if d := isInSlice(valX, y); d != nil {
+		if d := c.isInSlice(valX, y); d != nil {
 			return newDiffMsg(x, y, d.String())
 		}
 		return nil
@@ -91,19 +215,168 @@ func contains(x, y interface{}) differ {
 			return newMessagef("type mismatch %T %T", x, y)
 
 		}
-		if d := isInMap(valX, valY); d != nil {
+		if d := c.isInMap(valX, valY); d != nil {
+			return newDiffMsg(x, y, d.String())
+		}
+		return nil
+	case reflect.Float32, reflect.Float64:
+		fy, isNum := toFloat(valY)
+		if !c.approx || !isNum {
+			break
+		}
+		fx := valX.Float()
+		if math.Abs(fx-fy) <= math.Max(c.fraction*math.Min(math.Abs(fx), math.Abs(fy)), c.margin) {
+			return nil
+		}
+		return newDiff(x, y)
+	case reflect.Int64:
+		d, ok := x.(time.Duration)
+		e, isDur := y.(time.Duration)
+		if !ok || !isDur || !c.durApprox {
+			break
+		}
+		if diff := d - e; diff <= c.duration && -diff <= c.duration {
+			return nil
+		}
+		return newDiff(x, y)
+	case reflect.Pointer:
+		if !valY.IsValid() || valX.Type() != valY.Type() {
+			break
+		}
+		if valY.IsNil() { // nothing expected
+			return nil
+		}
+		if valX.IsNil() {
+			return newDiffMsg(x, y, fmt.Sprintf(" + <nil>\n - %+v", valY.Elem()))
+		}
+		// a pair seen again is a cycle, it matches if the rest of the values do
+		pair := ptrPair{typ: valX.Type(), x: valX.Pointer(), y: valY.Pointer()}
+		if c.visited[pair] {
+			return nil
+		}
+		c.visited[pair] = true
+		return c.contains(valX.Elem().Interface(), valY.Elem().Interface())
+	case reflect.Struct:
+		if !valY.IsValid() || valX.Type() != valY.Type() || hasEqualMethod(valX.Type()) {
+			break
+		}
+		if d := c.isInStruct(valX, valY); d != nil {
 			return newDiffMsg(x, y, d.String())
 		}
 		return nil
 	}
-	isEqual, s := Equal(x, y)
+	isEqual, s := c.equal(x, y)
 	if isEqual {
 		return nil
 	}
 	return newMessagef(s)
 }
 
-func isInMap(parent reflect.Value, child reflect.Value) differ {
+// toFloat converts any number to a float64 so an expected
+// int (10) can match an actual float (10.04) within a tolerance
+func toFloat(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// equal compares values that can't be contained with Equal and the tolerance options
+func (c *containsCfg) equal(x, y interface{}) (bool, string) {
+	if !c.approx && !c.durApprox {
+		return Equal(x, y)
+	}
+	opts := []func(interface{}) cmp.Option{AllowAllUnexported, EquateEmpty}
+	if c.approx {
+		opts = append(opts, ApproxFloat(c.fraction, c.margin))
+	}
+	if c.durApprox {
+		opts = append(opts, ApproxDuration(c.duration))
+	}
+	return EqualOpt(opts...)(x, y)
+}
+
+// isInString checks the expected string s against the actual string
+func (c *containsCfg) isInString(x interface{}, actual, s string) differ {
+	if c.is(RegexStrings) {
+		pattern := s
+		if c.is(IgnoreCase) {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return newMessagef("invalid regex %q: %v", s, err)
+		}
+		if re.MatchString(actual) {
+			return nil
+		}
+		return newDiffMsg(x, s, fmt.Sprintf(" + %v\n - /%v/", x, s))
+	}
+	a, e := actual, s
+	if c.is(IgnoreCase) {
+		a, e = strings.ToLower(a), strings.ToLower(e)
+	}
+	if (c.is(SubStrings) && strings.Contains(a, e)) || a == e {
+		return nil
+	}
+	return newDiff(x, s)
+}
+
+// isInStruct checks the fields of child that are not zero are contained
+// in the same fields of parent. Zero values are treated as wildcards.
+func (c *containsCfg) isInStruct(parent, child reflect.Value) differ {
+	parent, child = addressable(parent), addressable(child)
+	d := &structDiff{}
+	for i := 0; i < child.NumField(); i++ {
+		if child.Field(i).IsZero() {
+			continue
+		}
+		x, y := fieldValue(parent, i), fieldValue(child, i)
+		if r := c.contains(x, y); r != nil {
+			d.fields = append(d.fields, child.Type().Field(i).Name)
+			d.diffs = append(d.diffs, r.String())
+		}
+	}
+	if len(d.fields) > 0 {
+		return d
+	}
+	return nil
+}
+
+// hasEqualMethod is true for types like time.Time that define their own
+// equality with an Equal(T) bool method
+func hasEqualMethod(t reflect.Type) bool {
+	m, ok := t.MethodByName("Equal")
+	return ok && m.Type.NumIn() == 2 && m.Type.In(1) == t &&
+		m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool
+}
+
+// addressable returns an addressable copy of v so unexported fields can be read
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// fieldValue returns the value of the i'th field of the addressable struct v
+// including unexported (private) fields
+func fieldValue(v reflect.Value, i int) interface{} {
+	f := v.Field(i)
+	if !f.CanInterface() {
+		f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+	}
+	return f.Interface()
+}
+
+func (c *containsCfg) isInMap(parent reflect.Value, child reflect.Value) differ {
 	d := &mapDiff{values: make(map[interface{}][]string, 0)}
 	for _, key := range child.MapKeys() {
 		p := parent.MapIndex(key)
@@ -111,16 +384,26 @@ func isInMap(parent reflect.Value, child reflect.Value) differ {
 			d.values[key] = make([]string, 0)
 			continue
 		}
-		c := child.MapIndex(key)
-		if ok := contains(p.Interface(), c.Interface()); ok != nil {
+		v := child.MapIndex(key)
+		if ok := c.contains(p.Interface(), v.Interface()); ok != nil {
 			d.values[key] = append(d.values[key], ok.String())
 		}
 	}
+	if !c.is(SubMaps) {
+		for _, key := range parent.MapKeys() {
+			if !child.MapIndex(key).IsValid() {
+				d.extra = append(d.extra, key.Interface())
+			}
+		}
+		sort.Slice(d.extra, func(i, j int) bool {
+			return fmt.Sprint(d.extra[i]) < fmt.Sprint(d.extra[j])
+		})
+	}
 	return d.diffOrNil()
 }
 
-func isInSlice(parent reflect.Value, child ...interface{}) differ {
-	c := &collection{
+func (c *containsCfg) isInSlice(parent reflect.Value, child ...interface{}) differ {
+	col := &collection{
 		found:   make([]interface{}, 0),
 		missing: make([]interface{}, 0),
 	}
@@ -128,22 +411,197 @@ func isInSlice(parent reflect.Value, child ...interface{}) differ {
 		found := false
 		for i := 0; i < parent.Len(); i++ {
 			p := parent.Index(i)
-			if contains(p.Interface(), v) == nil {
+			if c.contains(p.Interface(), v) == nil {
 				found = true
-				c.found = append(c.found, v)
+				col.found = append(col.found, v)
 				break
 			}
 		}
 		if !found {
-			c.missing = append(c.missing, v)
+			col.missing = append(col.missing, v)
 		}
 	}
-	if len(c.missing) > 0 {
-		return c
+	if len(col.missing) > 0 {
+		return col
 	}
 	return nil
 }
 
+// isInOrder checks that child values are found in parent in the same order.
+// Each parent value can only match one child value.
+func (c *containsCfg) isInOrder(parent reflect.Value, child ...interface{}) differ {
+	col := &collection{
+		found:   make([]interface{}, 0),
+		missing: make([]interface{}, 0),
+	}
+	next := 0
+	for j, v := range child {
+		found := false
+		for i := next; i < parent.Len(); i++ {
+			if c.match(parent.Index(i).Interface(), v) {
+				found = true
+				next = i + 1
+				col.found = append(col.found, indexed{i, v})
+				break
+			}
+		}
+		if !found {
+			col.missing = append(col.missing, indexed{j, v})
+		}
+	}
+	if len(col.missing) > 0 {
+		return col
+	}
+	return nil
+}
+
+// isMultiset checks that every parent value matches exactly one child value.
+// Values are paired with a bipartite matching so the result doesn't depend on their order.
+func (c *containsCfg) isMultiset(parent reflect.Value, child ...interface{}) differ {
+	matches := make([][]int, len(child)) // parent indexes each child value matches
+	for j, v := range child {
+		for i := 0; i < parent.Len(); i++ {
+			if c.match(parent.Index(i).Interface(), v) {
+				matches[j] = append(matches[j], i)
+			}
+		}
+	}
+	pairs := make(map[int]int) // parent index -> child index
+	var assign func(j int, seen map[int]bool) bool
+	assign = func(j int, seen map[int]bool) bool {
+		for _, i := range matches[j] {
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			if k, used := pairs[i]; !used || assign(k, seen) {
+				pairs[i] = j
+				return true
+			}
+		}
+		return false
+	}
+
+	col := &collection{
+		found:   make([]interface{}, 0),
+		missing: make([]interface{}, 0),
+	}
+	for j, v := range child {
+		if !assign(j, make(map[int]bool)) {
+			col.missing = append(col.missing, indexed{j, v})
+		}
+	}
+	for i := 0; i < parent.Len(); i++ {
+		if j, ok := pairs[i]; ok {
+			col.found = append(col.found, indexed{i, child[j]})
+		} else {
+			col.extra = append(col.extra, indexed{i, parent.Index(i).Interface()})
+		}
+	}
+	if len(col.missing) > 0 || len(col.extra) > 0 {
+		return col
+	}
+	return nil
+}
+
+// isAt checks that the child values are contained in parent starting at index offset
+func (c *containsCfg) isAt(parent reflect.Value, offset int, child ...interface{}) differ {
+	col := &collection{
+		found:   make([]interface{}, 0),
+		missing: make([]interface{}, 0),
+	}
+	if offset < 0 || offset+len(child) > parent.Len() {
+		return newMessagef(" length %d < %d", parent.Len(), len(child))
+	}
+	for j, v := range child {
+		if c.match(parent.Index(offset+j).Interface(), v) {
+			col.found = append(col.found, indexed{offset + j, v})
+		} else {
+			col.missing = append(col.missing, indexed{offset + j, v})
+		}
+	}
+	if len(col.missing) > 0 {
+		return col
+	}
+	return nil
+}
+
+// isSameSlice checks that parent and child are the same length
+// and each child value is contained in the parent value at the same index
+func (c *containsCfg) isSameSlice(parent reflect.Value, child ...interface{}) differ {
+	col := &collection{
+		found:   make([]interface{}, 0),
+		missing: make([]interface{}, 0),
+	}
+	for i, v := range child {
+		if i < parent.Len() && c.contains(parent.Index(i).Interface(), v) == nil {
+			col.found = append(col.found, v)
+		} else {
+			col.missing = append(col.missing, v)
+		}
+	}
+	if len(col.missing) > 0 {
+		return col
+	}
+	if parent.Len() != len(child) {
+		return newMessagef(" length %d != %d", parent.Len(), len(child))
+	}
+	return nil
+}
+
+// Subsequence checks that the expected slice is found in the actual slice in the same order,
+// other values may be between them. ([1,3] -> [1,2,3])
+// Values are matched with Equal.
+func Subsequence(actual, expected interface{}) (bool, string) {
+	return compareSlices(actual, expected, func(x reflect.Value, y []interface{}) differ {
+		return exactValues.checker().isInOrder(x, y...)
+	})
+}
+
+// ElementsMatch checks that the actual and expected slices have the same values
+// ignoring order. Repeated values must appear the same number of times. ([1,2,2] -> [2,1,2])
+// Values are matched with Equal.
+func ElementsMatch(actual, expected interface{}) (bool, string) {
+	return compareSlices(actual, expected, func(x reflect.Value, y []interface{}) differ {
+		return exactValues.checker().isMultiset(x, y...)
+	})
+}
+
+// HasPrefix checks that the actual slice starts with the expected slice. ([1,2] -> [1,2,3])
+// Values are matched with Equal.
+func HasPrefix(actual, expected interface{}) (bool, string) {
+	return compareSlices(actual, expected, func(x reflect.Value, y []interface{}) differ {
+		return exactValues.checker().isAt(x, 0, y...)
+	})
+}
+
+// HasSuffix checks that the actual slice ends with the expected slice. ([2,3] -> [1,2,3])
+// Values are matched with Equal.
+func HasSuffix(actual, expected interface{}) (bool, string) {
+	return compareSlices(actual, expected, func(x reflect.Value, y []interface{}) differ {
+		return exactValues.checker().isAt(x, x.Len()-len(y), y...)
+	})
+}
+
+// compareSlices checks actual and expected are slices (or arrays) and compares them with fn
+func compareSlices(actual, expected interface{}, fn func(x reflect.Value, y []interface{}) differ) (bool, string) {
+	valX, valY := reflect.ValueOf(actual), reflect.ValueOf(expected)
+	isSlice := func(v reflect.Value) bool {
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	}
+	if !isSlice(valX) || !isSlice(valY) {
+		return false, fmt.Sprintf("type mismatch %T %T (slices expected)", actual, expected)
+	}
+	child := make([]interface{}, valY.Len())
+	for i := range child {
+		child[i] = valY.Index(i).Interface()
+	}
+	if d := fn(valX, child); d != nil {
+		return false, newDiffMsg(actual, expected, d.String()).String()
+	}
+	return true, ""
+}
+
 // Equal use the cmp.Diff method to check equality and display differences.
 // This method checks all unexpected values
 func Equal(actual, expected interface{}) (bool, string) {
@@ -159,12 +617,89 @@ func EqualOpt(optFns ...func(i interface{}) cmp.Option) func(actual, expected in
 		for _, fn := range optFns {
 			opts = append(opts, fn(actual))
 		}
+		rep := &comparerReporter{}
+		opts = append(opts, useComparer, cmp.Reporter(rep))
 
 		r := cmp.Diff(actual, expected, opts...)
-		return r == "", r
+		if r == "" {
+			return true, ""
+		}
+		return false, r + rep.String()
+	}
+}
+
+// useComparer compares values that implement the Comparer interface with their Equals method
+var useComparer = cmp.FilterValues(canCompare, cmp.Comparer(func(x, y Comparer) bool {
+	eq, _ := x.Equals(y)
+	return eq
+}))
+
+// canCompare is true if x's Equals method can be used.
+// nil values are left to cmp and empty slices and maps to EquateEmpty
+func canCompare(x, y Comparer) bool {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if isNil(vx) || isNil(vy) {
+		return false
+	}
+	switch vx.Kind() {
+	case reflect.Slice, reflect.Map:
+		return vx.Len() > 0 || vy.Len() > 0
+	}
+	return true
+}
+
+// isNil is true for nil pointers, maps, slices, interfaces, funcs and channels
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return !v.IsValid()
+}
+
+// comparerReporter records the differences described by the Equals method
+// of values that were not equal so they can be added to the cmp.Diff output
+type comparerReporter struct {
+	path  cmp.Path
+	diffs []string
+}
+
+func (r *comparerReporter) PushStep(ps cmp.PathStep) { r.path = append(r.path, ps) }
+
+func (r *comparerReporter) PopStep() { r.path = r.path[:len(r.path)-1] }
+
+func (r *comparerReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+	vx, vy := r.path.Last().Values()
+	if !vx.IsValid() || !vy.IsValid() || !vx.CanInterface() || !vy.CanInterface() {
+		return
+	}
+	x, ok := vx.Interface().(Comparer)
+	if !ok {
+		return
+	}
+	y, ok := vy.Interface().(Comparer)
+	if !ok || !canCompare(x, y) {
+		return
+	}
+	if _, d := x.Equals(y); d != "" {
+		name := r.path.String()
+		if name == "" {
+			name = fmt.Sprintf("%T", x)
+		}
+		r.diffs = append(r.diffs, fmt.Sprintf("%s: %s", name, d))
 	}
 }
 
+func (r *comparerReporter) String() string {
+	if len(r.diffs) == 0 {
+		return ""
+	}
+	return "Equals:\n  " + strings.Join(r.diffs, "\n  ") + "\n"
+}
+
 // AllowAllUnexported sets cmp.Diff to allow all unexported (private) variables
 func AllowAllUnexported(i interface{}) cmp.Option {
 	return cmp.AllowUnexported(findAllStructs(i, nil)...)
@@ -209,6 +744,36 @@ func ApproxTime(d time.Duration) func(interface{}) cmp.Option {
 	}
 }
 
+// ApproxFloat is a wrapper around the cmpopts.EquateApprox
+// it will consider float32 and float64 values equal if they are within
+// the margin or fraction of each other.
+// |x-y| ≤ max(fraction*min(|x|, |y|), margin)
+func ApproxFloat(fraction, margin float64) func(interface{}) cmp.Option {
+	return func(_ interface{}) cmp.Option {
+		return cmpopts.EquateApprox(fraction, margin)
+	}
+}
+
+// EquateNaNs is a wrapper around cmpopts.EquateNaNs
+// it considers float32 and float64 NaN values equal
+func EquateNaNs(i interface{}) cmp.Option {
+	return cmpopts.EquateNaNs()
+}
+
+// ApproxDuration considers time.Duration values equal if their
+// difference is less than or equal to d
+func ApproxDuration(d time.Duration) func(interface{}) cmp.Option {
+	return func(_ interface{}) cmp.Option {
+		return cmp.Comparer(func(x, y time.Duration) bool {
+			diff := x - y
+			if diff < 0 {
+				diff = -diff
+			}
+			return diff <= d
+		})
+	}
+}
+
 /*
 func IgnoreInterfaces(i ...interface{}) func(interface{}) cmp.Option {
 	return func(i interface{}) cmp.Option {
@@ -365,6 +930,7 @@ func newMessagef(s string, args ...interface{}) message {
 type collection struct {
 	found   []interface{}
 	missing []interface{}
+	extra   []interface{} // values in actual that were not expected
 }
 
 func (c *collection) String() (s string) {
@@ -373,13 +939,32 @@ func (c *collection) String() (s string) {
 		s += fmt.Sprintf(" %v,", v)
 	}
 	s = strings.TrimRight(s, " ∈,")
-	s += "\n -"
-	for _, v := range c.missing {
-		s += fmt.Sprintf(" %v,", v)
+	if len(c.missing) > 0 || len(c.extra) == 0 {
+		s += "\n -"
+		for _, v := range c.missing {
+			s += fmt.Sprintf(" %v,", v)
+		}
+		s = strings.TrimRight(s, ",")
+	}
+	if len(c.extra) > 0 {
+		s += "\n +"
+		for _, v := range c.extra {
+			s += fmt.Sprintf(" %v,", v)
+		}
 	}
 	return strings.Trim(s, ",\n")
 }
 
+// indexed is a slice value shown with its index
+type indexed struct {
+	i int
+	v interface{}
+}
+
+func (x indexed) String() string {
+	return fmt.Sprintf("[%d]%v", x.i, x.v)
+}
+
 type diff struct {
 	x   interface{}
 	y   interface{}
@@ -401,9 +986,23 @@ func (d *diff) String() string {
 	return fmt.Sprintf("%T ⊇ %T\n%s", d.x, d.y, d.msg)
 }
 
+// structDiff is a differ for structs that shows the fields that don't match
+type structDiff struct {
+	fields []string
+	diffs  []string
+}
+
+func (d *structDiff) String() (s string) {
+	for i, f := range d.fields {
+		s += fmt.Sprintf(" .%s: %s\n", f, strings.Replace(d.diffs[i], "\n", "\n    ", -1))
+	}
+	return strings.TrimRight(s, "\n")
+}
+
 // mapDiff is a differ for maps
 type mapDiff struct {
 	values map[interface{}][]string
+	extra  []interface{} // keys in actual that were not expected
 }
 
 func (d *mapDiff) String() (s string) {
@@ -419,12 +1018,15 @@ func (d *mapDiff) String() (s string) {
 		}
 		s += ":" + strings.Replace(sub, "\n", "\n    ", -1) + "\n"
 	}
+	for _, key := range d.extra {
+		s += fmt.Sprintf(" [%v]: unexpected key\n", key)
+	}
 
 	return strings.TrimRight(s, "\n")
 }
 
 func (d *mapDiff) diffOrNil() differ {
-	if len(d.values) > 0 {
+	if len(d.values) > 0 || len(d.extra) > 0 {
 		return d
 	}
 	return nil
@@ -2,7 +2,11 @@ package trial
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -290,6 +294,54 @@ func TestComparerOptions(t *testing.T) {
 			},
 			Expected: true,
 		},
+		"ApproxFloat": {
+			Input: input{
+				fn: EqualOpt(AllowAllUnexported, ApproxFloat(0.01, 0)),
+				v1: tStruct{Child: child{Float: 100}, kid: child{pFloat: 1.001}},
+				v2: tStruct{Child: child{Float: 100.5}, kid: child{pFloat: 1}},
+			},
+			Expected: true,
+		},
+		"ApproxFloat outside": {
+			Input: input{
+				fn: EqualOpt(ApproxFloat(0, 0.1)),
+				v1: []float64{1, 2},
+				v2: []float64{1.05, 2.2},
+			},
+			ExpectedErr: errors.New("2.2"),
+		},
+		"EquateNaNs": {
+			Input: input{
+				fn: EqualOpt(EquateNaNs),
+				v1: map[string]float64{"a": math.NaN()},
+				v2: map[string]float64{"a": math.NaN()},
+			},
+			Expected: true,
+		},
+		"NaN not equal": {
+			Input: input{
+				fn: Equal,
+				v1: math.NaN(),
+				v2: math.NaN(),
+			},
+			ShouldErr: true,
+		},
+		"ApproxDuration": {
+			Input: input{
+				fn: EqualOpt(ApproxDuration(time.Second)),
+				v1: struct{ D []time.Duration }{D: []time.Duration{time.Minute, 0}},
+				v2: struct{ D []time.Duration }{D: []time.Duration{time.Minute + time.Second, -time.Millisecond}},
+			},
+			Expected: true,
+		},
+		"ApproxDuration outside": {
+			Input: input{
+				fn: EqualOpt(ApproxDuration(time.Second)),
+				v1: 10 * time.Second,
+				v2: 12 * time.Second,
+			},
+			ShouldErr: true,
+		},
 	}
 	New(fn, cases).SubTest(t)
 
@@ -445,6 +497,517 @@ func TestContainsFn(t *testing.T) {
 
 }
 
+func TestContainsOpt(t *testing.T) {
+	type input struct {
+		opts []ContainsOption
+		x    any
+		y    any
+	}
+	fn := func(in input) (string, error) {
+		if ok, diff := ContainsOpt(in.opts...)(in.x, in.y); !ok {
+			return diff, nil
+		}
+		return "match", nil
+	}
+	cases := Cases[input, string]{
+		"no options equal strings": {
+			Input:    input{x: "abc", y: "abc"},
+			Expected: "match",
+		},
+		"no options substring": {
+			Input:    input{x: "abcdef", y: "bcd"},
+			Expected: "string ⊇ string\n + abcdef\n - bcd",
+		},
+		"substring": {
+			Input:    input{opts: []ContainsOption{SubStrings}, x: "abcdef", y: "bcd"},
+			Expected: "match",
+		},
+		"ignore case": {
+			Input:    input{opts: []ContainsOption{IgnoreCase}, x: "Hello", y: "hELLO"},
+			Expected: "match",
+		},
+		"ignore case substring": {
+			Input:    input{opts: []ContainsOption{SubStrings, IgnoreCase}, x: "Hello World", y: "WORLD"},
+			Expected: "match",
+		},
+		"regex": {
+			Input:    input{opts: []ContainsOption{RegexStrings}, x: "id 1234 not found", y: `id \d+ not`},
+			Expected: "match",
+		},
+		"regex ignore case": {
+			Input:    input{opts: []ContainsOption{RegexStrings, IgnoreCase}, x: "ID 1234", y: `^id \d+$`},
+			Expected: "match",
+		},
+		"regex no match": {
+			Input:    input{opts: []ContainsOption{RegexStrings}, x: "id abc", y: `id \d+`},
+			Expected: "string ⊇ string\n + id abc\n - /id \\d+/",
+		},
+		"invalid regex": {
+			Input:    input{opts: []ContainsOption{RegexStrings}, x: "abc", y: `a(`},
+			Expected: `invalid regex "a("`,
+		},
+		"exact slice": {
+			Input:    input{x: []string{"a", "b"}, y: []string{"a", "b"}},
+			Expected: "match",
+		},
+		"exact slice out of order": {
+			Input:    input{x: []string{"a", "b"}, y: []string{"b", "a"}},
+			Expected: "[]string ⊇ []string\n - b, a",
+		},
+		"exact slice length": {
+			Input:    input{x: []int{1, 2, 3}, y: []int{1, 2}},
+			Expected: "[]int ⊇ []int\n length 3 != 2",
+		},
+		"sub slice": {
+			Input:    input{opts: []ContainsOption{SubSlices}, x: []int{1, 2, 3}, y: []int{3, 1}},
+			Expected: "match",
+		},
+		"ordered slice": {
+			Input:    input{opts: []ContainsOption{OrderedSlices}, x: []int{1, 2, 3, 4}, y: []int{1, 3, 4}},
+			Expected: "match",
+		},
+		"ordered slice out of order": {
+			Input:    input{opts: []ContainsOption{OrderedSlices}, x: []int{1, 2, 3, 4}, y: []int{3, 1}},
+			Expected: "[]int ⊇ []int\n ∈ [2]3\n - [1]1",
+		},
+		"slice of strings ignore case": {
+			Input:    input{opts: []ContainsOption{SubSlices, IgnoreCase}, x: []string{"Apple", "Pear"}, y: []string{"pear"}},
+			Expected: "match",
+		},
+		"exact map": {
+			Input:    input{x: map[string]int{"a": 1, "b": 2}, y: map[string]int{"a": 1}},
+			Expected: "map[string]int ⊇ map[string]int\n [b]: unexpected key",
+		},
+		"sub map": {
+			Input:    input{opts: []ContainsOption{SubMaps}, x: map[string]int{"a": 1, "b": 2}, y: map[string]int{"a": 1}},
+			Expected: "match",
+		},
+		"sub map of substrings": {
+			Input: input{
+				opts: []ContainsOption{SubMaps, SubStrings},
+				x:    map[string]string{"a": "hello world", "b": "bye"},
+				y:    map[string]string{"a": "world"},
+			},
+			Expected: "match",
+		},
+		"float tolerance": {
+			Input: input{
+				opts: []ContainsOption{SubMaps, FloatTolerance(0.01, 0)},
+				x:    map[string]any{"mean": 10.04, "count": 3},
+				y:    map[string]any{"mean": 10.0},
+			},
+			Expected: "match",
+		},
+		"float tolerance outside": {
+			Input:    input{opts: []ContainsOption{SubSlices, FloatTolerance(0, 0.001)}, x: []float32{1.5, 2.5}, y: []float64{2.6}},
+			Expected: "[]float32 ⊇ []float64\n - 2.6",
+		},
+		"float tolerance struct": {
+			Input: input{
+				opts: []ContainsOption{FloatTolerance(0, 0.5)},
+				x:    struct{ A, B float64 }{A: 1.2, B: 3},
+				y:    struct{ A, B float64 }{A: 1},
+			},
+			Expected: "match",
+		},
+		"float tolerance int expected": {
+			Input: input{
+				opts: []ContainsOption{SubMaps, FloatTolerance(0, 0.1)},
+				x:    map[string]any{"mean": 10.04},
+				y:    map[string]any{"mean": 10},
+			},
+			Expected: "match",
+		},
+		"duration tolerance": {
+			Input: input{
+				opts: []ContainsOption{SubSlices, DurationTolerance(time.Second)},
+				x:    []time.Duration{time.Minute, time.Hour},
+				y:    []time.Duration{time.Hour + 500*time.Millisecond},
+			},
+			Expected: "match",
+		},
+		"duration tolerance outside": {
+			Input: input{
+				opts: []ContainsOption{DurationTolerance(time.Second)},
+				x:    map[string]time.Duration{"a": time.Minute},
+				y:    map[string]time.Duration{"a": time.Minute - 2*time.Second},
+			},
+			Expected: "[a]: time.Duration ⊇ time.Duration",
+		},
+		"duration tolerance in array": {
+			Input: input{
+				opts: []ContainsOption{DurationTolerance(time.Second)},
+				x:    struct{ D [2]time.Duration }{D: [2]time.Duration{time.Second, 0}},
+				y:    struct{ D [2]time.Duration }{D: [2]time.Duration{0, time.Second}},
+			},
+			Expected: "match",
+		},
+		"contains durations exact": {
+			Input:    input{opts: []ContainsOption{SubSlices}, x: []time.Duration{time.Second}, y: []time.Duration{time.Second + 1}},
+			Expected: "- 1.000000001s",
+		},
+		"floats exact": {
+			Input:    input{x: 1.0, y: 1.01},
+			Expected: "1.01",
+		},
+		"same as Contains": {
+			Input: input{
+				opts: []ContainsOption{SubStrings, SubSlices, SubMaps},
+				x:    map[string][]string{"a": {"hello", "world"}},
+				y:    map[string][]string{"a": {"wor"}},
+			},
+			Expected: "match",
+		},
+	}
+	New(fn, cases).Comparer(Contains).SubTest(t)
+}
+
+func TestContains_Struct(t *testing.T) {
+	type address struct {
+		City string
+		Zip  string
+	}
+	type user struct {
+		ID      int
+		Name    string
+		Address *address
+		Tags    []string
+		Meta    map[string]any
+		Created time.Time
+		private string
+	}
+	actual := user{
+		ID:      1,
+		Name:    "Jane Doe",
+		Address: &address{City: "Salt Lake City", Zip: "84101"},
+		Tags:    []string{"admin", "owner"},
+		Meta:    map[string]any{"plan": "pro", "seats": 5},
+		Created: Time(time.RFC3339, "2023-01-02T03:04:05Z"),
+		private: "secret",
+	}
+	// pair checks x contains y in place of actual
+	type pair struct{ x, y any }
+	fn := func(expected any) (string, error) {
+		x := any(actual)
+		if p, ok := expected.(pair); ok {
+			x, expected = p.x, p.y
+		}
+		if ok, diff := Contains(x, expected); !ok {
+			return diff, nil
+		}
+		return "match", nil
+	}
+	cases := Cases[any, string]{
+		"zero struct": {
+			Input:    user{},
+			Expected: "match",
+		},
+		"one field": {
+			Input:    user{ID: 1},
+			Expected: "match",
+		},
+		"substring field": {
+			Input:    user{Name: "Doe"},
+			Expected: "match",
+		},
+		"nested pointer": {
+			Input:    user{Address: &address{City: "Salt Lake"}},
+			Expected: "match",
+		},
+		"sub slice and map": {
+			Input:    user{Tags: []string{"owner"}, Meta: map[string]any{"seats": 5}},
+			Expected: "match",
+		},
+		"unexported field": {
+			Input:    user{private: "sec"},
+			Expected: "match",
+		},
+		"time": {
+			Input:    user{Created: Time(time.RFC3339, "2023-01-02T03:04:05Z")},
+			Expected: "match",
+		},
+		"pointer to struct": {
+			Input:    &user{ID: 1},
+			Expected: "&trial.user{ID: 1}",
+		},
+		"field mismatch": {
+			Input:    user{ID: 2, Name: "Jane"},
+			Expected: " .ID: ",
+		},
+		"nested mismatch": {
+			Input:    user{Address: &address{Zip: "84102"}},
+			Expected: "trial.user ⊇ trial.user\n .Address: trial.address ⊇ trial.address\n     .Zip: string ⊇ string\n         + 84101\n         - 84102",
+		},
+		"missing tag": {
+			Input:    user{Tags: []string{"guest"}},
+			Expected: " .Tags: []string ⊇ []string\n     - guest",
+		},
+		"nil for pointer": {
+			Input:    pair{x: map[string]any{"a": &address{}}, y: map[string]any{"a": nil}},
+			Expected: "&trial.address{}",
+		},
+		"nil for struct": {
+			Input:    pair{x: map[string]any{"a": address{}}, y: map[string]any{"a": nil}},
+			Expected: "trial.address{}",
+		},
+		"nil in slice": {
+			Input:    pair{x: []any{address{}}, y: []any{nil}},
+			Expected: "[]interface {} ⊇ []interface {}\n - <nil>",
+		},
+	}
+	New(fn, cases).Comparer(Contains).SubTest(t)
+
+	// a slice of structs
+	users := []user{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	if ok, diff := Contains(users, []user{{Name: "b"}}); !ok {
+		t.Errorf("FAIL: slice of structs %s", diff)
+	}
+	// a nil pointer in actual
+	if ok, _ := Contains(user{}, user{Address: &address{City: "a"}}); ok {
+		t.Error("FAIL: nil pointer should not match")
+	}
+}
+
+func TestSliceComparers(t *testing.T) {
+	type input struct {
+		fn CompareFunc
+		x  any
+		y  any
+	}
+	fn := func(in input) (string, error) {
+		if ok, diff := in.fn(in.x, in.y); !ok {
+			return diff, nil
+		}
+		return "match", nil
+	}
+	cases := Cases[input, string]{
+		"subsequence": {
+			Input:    input{fn: Subsequence, x: []int{1, 2, 3, 4}, y: []int{1, 3, 4}},
+			Expected: "match",
+		},
+		"subsequence out of order": {
+			Input:    input{fn: Subsequence, x: []int{1, 2, 3}, y: []int{1, 3, 2}},
+			Expected: "[]int ⊇ []int\n ∈ [0]1, [2]3\n - [2]2",
+		},
+		"subsequence repeated": {
+			Input:    input{fn: Subsequence, x: []string{"a", "b"}, y: []string{"a", "a"}},
+			Expected: "[]string ⊇ []string\n ∈ [0]a\n - [1]a",
+		},
+		"subsequence of structs": {
+			Input:    input{fn: Subsequence, x: []testPanic{{1}, {2}, {3}}, y: []testPanic{{1}, {3}}},
+			Expected: "match",
+		},
+		"elements match": {
+			Input:    input{fn: ElementsMatch, x: []int{1, 2, 2, 3}, y: []int{2, 3, 1, 2}},
+			Expected: "match",
+		},
+		"elements counts": {
+			Input:    input{fn: ElementsMatch, x: []int{1, 2, 3}, y: []int{1, 1, 2, 3}},
+			Expected: "[]int ⊇ []int\n ∈ [0]1, [1]2, [2]3\n - [1]1",
+		},
+		"elements extra": {
+			Input:    input{fn: ElementsMatch, x: []int{1, 2, 3}, y: []int{3, 1}},
+			Expected: "[]int ⊇ []int\n ∈ [0]1, [2]3\n + [1]2",
+		},
+		"elements missing and extra": {
+			Input:    input{fn: ElementsMatch, x: []string{"a", "b"}, y: []string{"a", "c"}},
+			Expected: "[]string ⊇ []string\n ∈ [0]a\n - [1]c\n + [1]b",
+		},
+		"elements are equal": {
+			// values are not substrings like Contains
+			Input:    input{fn: ElementsMatch, x: []string{"ab", "a"}, y: []string{"a", "b"}},
+			Expected: "[]string ⊇ []string\n ∈ [1]a\n - [1]b\n + [0]ab",
+		},
+		"elements substring": {
+			Input:    input{fn: ElementsMatch, x: []string{"apple"}, y: []string{"a"}},
+			Expected: "[]string ⊇ []string\n - [0]a\n + [0]apple",
+		},
+		"elements partial struct": {
+			Input:    input{fn: ElementsMatch, x: []testPanic{{1}}, y: []testPanic{{}}},
+			Expected: "[]trial.testPanic ⊇ []trial.testPanic\n - [0]{0}\n + [0]{1}",
+		},
+		"prefix": {
+			Input:    input{fn: HasPrefix, x: []int{1, 2, 3}, y: []int{1, 2}},
+			Expected: "match",
+		},
+		"prefix mismatch": {
+			Input:    input{fn: HasPrefix, x: []int{1, 2, 3}, y: []int{1, 3}},
+			Expected: "[]int ⊇ []int\n ∈ [0]1\n - [1]3",
+		},
+		"prefix substring": {
+			Input:    input{fn: HasPrefix, x: []string{"apple", "x"}, y: []string{"p"}},
+			Expected: "[]string ⊇ []string\n - [0]p",
+		},
+		"subsequence subset": {
+			Input:    input{fn: Subsequence, x: [][]int{{1, 2}, {3}}, y: [][]int{{2}, {3}}},
+			Expected: "[][]int ⊇ [][]int\n ∈ [1][3]\n - [0][2]",
+		},
+		"prefix too long": {
+			Input:    input{fn: HasPrefix, x: []int{1}, y: []int{1, 2}},
+			Expected: "[]int ⊇ []int\n length 1 < 2",
+		},
+		"suffix": {
+			Input:    input{fn: HasSuffix, x: [3]int{1, 2, 3}, y: []int{2, 3}},
+			Expected: "match",
+		},
+		"suffix mismatch": {
+			Input:    input{fn: HasSuffix, x: []int{1, 2, 3}, y: []int{1, 2}},
+			Expected: "[]int ⊇ []int\n - [1]1, [2]2",
+		},
+		"not a slice": {
+			Input:    input{fn: HasSuffix, x: "abc", y: []string{"c"}},
+			Expected: "type mismatch string []string (slices expected)",
+		},
+	}
+	New(fn, cases).Comparer(Contains).SubTest(t)
+}
+
+func TestContains_Cycle(t *testing.T) {
+	type node struct {
+		Name     string
+		Parent   *node
+		Children []*node
+	}
+	tree := func(child string) *node {
+		root := &node{Name: "root"}
+		root.Children = []*node{{Name: child, Parent: root}}
+		return root
+	}
+	fn := func(in [2]*node) (bool, error) {
+		ok, diff := Contains(in[0], in[1])
+		if !ok {
+			return false, errors.New(diff)
+		}
+		return true, nil
+	}
+	cases := Cases[[2]*node, bool]{
+		"same": {
+			Input:    [2]*node{tree("a"), tree("a")},
+			Expected: true,
+		},
+		"substring": {
+			Input:    [2]*node{tree("apple"), tree("pp")},
+			Expected: true,
+		},
+		"different": {
+			Input:       [2]*node{tree("a"), tree("b")},
+			ExpectedErr: errors.New(".Children: []*trial.node ⊇ []*trial.node"),
+		},
+	}
+	New(fn, cases).SubTest(t)
+}
+
+// money is compared by its numeric value ("1.5" == "1.50")
+type money string
+
+func (m money) Equals(v interface{}) (bool, string) {
+	x, _ := strconv.ParseFloat(string(m), 64)
+	y, _ := strconv.ParseFloat(string(v.(money)), 64)
+	if x == y {
+		return true, ""
+	}
+	return false, fmt.Sprintf("$%.2f != $%.2f", x, y)
+}
+
+// caseID is compared ignoring case
+type caseID string
+
+func (c caseID) Equals(v interface{}) (bool, string) {
+	if strings.EqualFold(string(c), string(v.(caseID))) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%q != %q (ignoring case)", c, v)
+}
+
+// lowerID uses Equal on its underlying type to compare ignoring case
+type lowerID string
+
+func (l lowerID) Equals(v interface{}) (bool, string) {
+	return Equal(strings.ToLower(string(l)), strings.ToLower(string(v.(lowerID))))
+}
+
+func TestComparer(t *testing.T) {
+	type item struct {
+		ID    caseID
+		Price money
+		Qty   int
+	}
+	type input struct {
+		fn CompareFunc
+		v1 any
+		v2 any
+	}
+	fn := func(in input) (string, error) {
+		if eq, diff := in.fn(in.v1, in.v2); !eq {
+			return diff, nil
+		}
+		return "equal", nil
+	}
+	cases := Cases[input, string]{
+		"equal": {
+			Input:    input{fn: Equal, v1: money("1.50"), v2: money("1.5")},
+			Expected: "equal",
+		},
+		"equal not": {
+			Input:    input{fn: Equal, v1: money("1.50"), v2: money("2")},
+			Expected: "Equals:\n  trial.money: $1.50 != $2.00",
+		},
+		"equal nested": {
+			Input: input{
+				fn: Equal,
+				v1: []item{{ID: "ABC", Price: "1.0", Qty: 1}},
+				v2: []item{{ID: "abc", Price: "1", Qty: 1}},
+			},
+			Expected: "equal",
+		},
+		"equal nested diff": {
+			Input: input{
+				fn: Equal,
+				v1: item{ID: "ABC", Price: "1.0", Qty: 1},
+				v2: item{ID: "abd", Price: "1", Qty: 1},
+			},
+			Expected: "Equals:\n  ID: \"ABC\" != \"abd\" (ignoring case)",
+		},
+		"equal empty slice": {
+			Input:    input{fn: Equal, v1: []money{}, v2: []money(nil)},
+			Expected: "equal",
+		},
+		"equal opt": {
+			Input: input{
+				fn: EqualOpt(IgnoreFields("Qty")),
+				v1: item{ID: "a", Price: "3.00", Qty: 1},
+				v2: item{ID: "A", Price: "3", Qty: 2},
+			},
+			Expected: "equal",
+		},
+		"equals calls Equal": {
+			Input:    input{fn: Equal, v1: []lowerID{"ABC"}, v2: []lowerID{"abc"}},
+			Expected: "equal",
+		},
+		"equals calls Equal diff": {
+			Input:    input{fn: Equal, v1: lowerID("ABC"), v2: lowerID("abd")},
+			Expected: "Equals:\n  trial.lowerID: ",
+		},
+		"contains with Equals calling Equal": {
+			Input:    input{fn: Contains, v1: map[string]lowerID{"a": "X", "b": "Y"}, v2: map[string]lowerID{"a": "x"}},
+			Expected: "equal",
+		},
+		"contains slice": {
+			Input:    input{fn: Contains, v1: []caseID{"ABC", "DEF"}, v2: caseID("def")},
+			Expected: "equal",
+		},
+		"contains map": {
+			Input:    input{fn: Contains, v1: map[string]money{"a": "1.00", "b": "2"}, v2: map[string]money{"a": "1"}},
+			Expected: "equal",
+		},
+		"contains diff": {
+			Input:    input{fn: Contains, v1: map[string]money{"a": "1.00"}, v2: map[string]money{"a": "1.01"}},
+			Expected: "$1.00 != $1.01",
+		},
+	}
+	New(fn, cases).Comparer(Contains).SubTest(t)
+}
+
 func TestCmpFuncs(t *testing.T) {
 	fn := func(in Input) (string, error) {
 		_, s := CmpFuncs(in.Slice(0).Interface(), in.Slice(1).Interface())
@@ -0,0 +1,171 @@
+package trial
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fuzzTypes are the argument types supported by testing.F
+var fuzzTypes = map[reflect.Kind]reflect.Type{
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+}
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// Fuzz seeds the fuzz corpus with the Input of every case and fuzzes the test function.
+// In must be a fuzzable type (string, []byte, bool, ints, uints and floats) or a struct
+// with only exported fields of those types.
+// Panics and timeouts fail the same as a case. invariant checks the result of each input,
+// when nil only panics and timeouts fail.
+// BeforeEach and AfterEach are called for every input with the name "fuzz".
+//
+//	func FuzzParse(f *testing.F) {
+//		trial.New(parse, cases).Fuzz(f, func(in string, out int, err error) error {...})
+//	}
+func (t *Trial[In, Out]) Fuzz(f *testing.F, invariant func(In, Out, error) error) {
+	f.Helper()
+	typ := reflect.TypeOf((*In)(nil)).Elem()
+	args, err := fuzzArgs(typ)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, name := range t.names() {
+		f.Add(fuzzValues(reflect.ValueOf(t.cases[name].Input), args)...)
+	}
+
+	// the invariant is checked inside the case so it is covered by the panic and timeout handling
+	check := &Trial[In, fuzzOut]{
+		testFn: func(ctx context.Context, in In) (fuzzOut, error) {
+			out, err := t.testFn(ctx, in)
+			o := fuzzOut{out: out, err: err}
+			if invariant == nil {
+				return o, nil
+			}
+			if err := invariant(in, out, err); err != nil {
+				return o, fmt.Errorf("invariant: %w", err)
+			}
+			return o, nil
+		},
+		// there is no expected output, only the invariant is checked
+		equalFn:    func(any, any) (bool, string) { return true, "" },
+		timeout:    t.timeout,
+		cancelable: t.cancelable,
+		grace:      t.grace,
+		beforeEach: t.beforeEach,
+	}
+
+	in := append([]reflect.Type{reflect.TypeOf((*testing.T)(nil))}, args...)
+	fn := reflect.MakeFunc(reflect.FuncOf(in, nil, false), func(vals []reflect.Value) []reflect.Value {
+		tb := vals[0].Interface().(*testing.T)
+		tb.Helper()
+		input := fuzzInput[In](typ, vals[1:])
+		r := check.testCase("fuzz", Case[In, fuzzOut]{Input: input})
+		if t.afterEach != nil {
+			t.afterEach("fuzz", fuzzResult[Out](r))
+		}
+		if !r.Success {
+			tb.Errorf("%s\ninput: %+v", t.format(r.status(), r.Message), input)
+		}
+		return nil
+	})
+	f.Fuzz(fn.Interface())
+}
+
+// fuzzOut is the output and error of the test function for a fuzz input
+// out is any as a generic fuzzOut[Out] would be an instantiation cycle of Trial
+type fuzzOut struct {
+	out any
+	err error
+}
+
+// fuzzResult converts the result of a fuzz input to the result of the test function
+// given to AfterEach. Err is the error returned by the test function, a failed
+// invariant is only in the Message.
+func fuzzResult[Out any](r Result[fuzzOut]) Result[Out] {
+	out, _ := r.Output.out.(Out) // nil after a panic or timeout
+	return Result[Out]{
+		Name:       r.Name,
+		Input:      r.Input,
+		Success:    r.Success,
+		TimedOut:   r.TimedOut,
+		Message:    r.Message,
+		Output:     out,
+		Err:        r.Output.err,
+		Panic:      r.Panic,
+		Stack:      r.Stack,
+		Diff:       r.Diff,
+		Duration:   r.Duration,
+		panicCheck: r.panicCheck,
+		panicked:   r.panicked,
+	}
+}
+
+// fuzzArgs are the fuzz argument types used to create the type t
+func fuzzArgs(t reflect.Type) ([]reflect.Type, error) {
+	if a, ok := fuzzArg(t); ok {
+		return []reflect.Type{a}, nil
+	}
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return nil, fmt.Errorf("%v is not fuzzable", t)
+	}
+	args := make([]reflect.Type, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		a, ok := fuzzArg(f.Type)
+		if !ok || !f.IsExported() {
+			return nil, fmt.Errorf("%v field %s (%v) is not fuzzable", t, f.Name, f.Type)
+		}
+		args = append(args, a)
+	}
+	return args, nil
+}
+
+// fuzzArg is the fuzz argument type for a (possibly named) basic type
+func fuzzArg(t reflect.Type) (reflect.Type, bool) {
+	if t.ConvertibleTo(bytesType) && t.Kind() == reflect.Slice {
+		return bytesType, true
+	}
+	a, ok := fuzzTypes[t.Kind()]
+	return a, ok
+}
+
+// fuzzValues splits v into the fuzz arguments
+func fuzzValues(v reflect.Value, args []reflect.Type) []any {
+	if v.Kind() != reflect.Struct {
+		return []any{v.Convert(args[0]).Interface()}
+	}
+	vals := make([]any, len(args))
+	for i := range args {
+		vals[i] = v.Field(i).Convert(args[i]).Interface()
+	}
+	return vals
+}
+
+// fuzzInput creates the input from the fuzz arguments
+func fuzzInput[In any](t reflect.Type, vals []reflect.Value) In {
+	var in In
+	v := reflect.ValueOf(&in).Elem()
+	if t.Kind() != reflect.Struct {
+		v.Set(vals[0].Convert(t))
+		return in
+	}
+	for i, a := range vals {
+		v.Field(i).Set(a.Convert(t.Field(i).Type))
+	}
+	return in
+}
@@ -0,0 +1,119 @@
+package trial
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func FuzzTrial(f *testing.F) {
+	fn := func(s string) (string, error) {
+		return strings.TrimSpace(s), nil
+	}
+	cases := Cases[string, string]{
+		"spaces": {Input: "  a b  ", Expected: "a b"},
+		"tabs":   {Input: "\ta\n", Expected: "a"},
+		"blank":  {Input: ""},
+	}
+	New(fn, cases).Fuzz(f, func(in string, out string, err error) error {
+		if !strings.Contains(in, out) || strings.TrimSpace(out) != out {
+			return fmt.Errorf("%q is not trimmed from %q", out, in)
+		}
+		return nil
+	})
+}
+
+func FuzzTrial_Struct(f *testing.F) {
+	type id string
+	type input struct {
+		ID    id
+		Count uint8
+		Data  []byte
+	}
+	fn := func(in input) (string, error) {
+		return string(in.ID) + strconv.Itoa(int(in.Count)) + string(in.Data), nil
+	}
+	cases := Cases[input, string]{
+		"all":   {Input: input{ID: "a", Count: 1, Data: []byte("b")}, Expected: "a1b"},
+		"empty": {Input: input{}, Expected: "0"},
+	}
+	New(fn, cases).Fuzz(f, nil)
+}
+
+func FuzzTrial_Hooks(f *testing.F) {
+	cases := Cases[string, int]{
+		"number": {Input: "12", Expected: 12},
+		"word":   {Input: "a", ShouldErr: true},
+	}
+	var results []Result[int]
+	f.Cleanup(func() {
+		var errs int
+		for _, r := range results {
+			in := r.Input.(string)
+			n, err := strconv.Atoi(in)
+			if r.Name != "fuzz" || in != strings.TrimSpace(in) || r.Output != n || (r.Err == nil) != (err == nil) {
+				f.Errorf("FAIL: after each %+v", r)
+			}
+			if r.Err != nil {
+				errs++
+			}
+		}
+		// with -fuzz the inputs are run by worker processes
+		fuzzing := flag.Lookup("test.fuzz").Value.String() != ""
+		if !fuzzing && (len(results) < len(cases) || errs == 0) {
+			f.Errorf("FAIL: after each called %d times with %d errors", len(results), errs)
+		}
+	})
+	New(strconv.Atoi, cases).
+		BeforeEach(func(_ string, in *string) { *in = strings.TrimSpace(*in) }).
+		AfterEach(func(_ string, r Result[int]) { results = append(results, r) }).
+		Fuzz(f, nil)
+}
+
+func TestFuzzArgs(t *testing.T) {
+	type raw []byte
+	fn := func(v any) ([]reflect.Type, error) {
+		return fuzzArgs(reflect.TypeOf(v))
+	}
+	cases := Cases[any, []reflect.Type]{
+		"string": {
+			Input:    "",
+			Expected: []reflect.Type{reflect.TypeOf("")},
+		},
+		"named bytes": {
+			Input:    raw{},
+			Expected: []reflect.Type{reflect.TypeOf([]byte{})},
+		},
+		"struct": {
+			Input: struct {
+				A int
+				B float64
+				C bool
+			}{},
+			Expected: []reflect.Type{reflect.TypeOf(0), reflect.TypeOf(0.0), reflect.TypeOf(false)},
+		},
+		"map": {
+			Input:       map[string]int{},
+			ExpectedErr: errors.New("map[string]int is not fuzzable"),
+		},
+		"unexported field": {
+			Input:       struct{ a int }{},
+			ExpectedErr: errors.New("field a (int) is not fuzzable"),
+		},
+		"slice field": {
+			Input:       struct{ A []int }{},
+			ExpectedErr: errors.New("field A ([]int) is not fuzzable"),
+		},
+		"empty struct": {
+			Input:       struct{}{},
+			ExpectedErr: errors.New("struct {} is not fuzzable"),
+		},
+	}
+	New(fn, cases).Comparer(func(actual, expected any) (bool, string) {
+		return reflect.DeepEqual(actual, expected), ""
+	}).SubTest(t)
+}
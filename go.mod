@@ -2,4 +2,7 @@ module github.com/hydronica/trial
 
 go 1.18
 
-require github.com/google/go-cmp v0.6.0
+require (
+	github.com/google/go-cmp v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,88 @@
+package trial
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var updateFlag = flag.Bool("trial.update", false, "rewrite trial golden files with the actual output")
+
+// Golden compares the output of each case with a golden file instead of Case.Expected.
+// The files are stored as <dir>/<TestName>/<case>.golden and are rewritten
+// with the actual output when tests are run with -trial.update, -update (if defined
+// by the test package) or TRIAL_UPDATE=1.
+// []byte and string outputs are stored as is, all other types as indented JSON.
+func (t *Trial[In, Out]) Golden(dir string) *Trial[In, Out] {
+	t.goldenDir = dir
+	return t
+}
+
+// updateGolden is true when the golden files should be rewritten
+func updateGolden() bool {
+	if *updateFlag {
+		return true
+	}
+	if b, _ := strconv.ParseBool(os.Getenv("TRIAL_UPDATE")); b {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		b, _ := strconv.ParseBool(f.Value.String())
+		return b
+	}
+	return false
+}
+
+// goldenPath of the file for a case
+func (t *Trial[In, Out]) goldenPath(name string) string {
+	return filepath.Join(t.goldenDir, fileName(t.suite), fileName(name)+".golden")
+}
+
+// golden compares the actual value with the golden file for the case
+func (t *Trial[In, Out]) golden(name string, actual Out) (bool, string) {
+	b, err := marshalGolden(actual)
+	if err != nil {
+		return false, "golden: " + err.Error()
+	}
+	path := t.goldenPath(name)
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return false, "golden: " + err.Error()
+		}
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			return false, "golden: " + err.Error()
+		}
+		return true, ""
+	}
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Sprintf("golden file %s not found, run with -trial.update or TRIAL_UPDATE=1 to create it", path)
+	} else if err != nil {
+		return false, "golden: " + err.Error()
+	}
+	if diff := cmp.Diff(string(b), string(expected)); diff != "" {
+		return false, fmt.Sprintf("%s\n%s", path, diff)
+	}
+	return true, ""
+}
+
+// marshalGolden serializes the value stored in a golden file
+func marshalGolden(v any) ([]byte, error) {
+	switch x := v.(type) {
+	case []byte:
+		return x, nil
+	case string:
+		return []byte(x), nil
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
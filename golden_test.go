@@ -0,0 +1,67 @@
+package trial
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrial_Golden(t *testing.T) {
+	type report struct {
+		Title string
+		Rows  []int
+	}
+	dir := t.TempDir()
+	rows := []int{1, 2, 3}
+	fn := func(title string) (report, error) {
+		return report{Title: title, Rows: rows}, nil
+	}
+	cases := Cases[string, report]{
+		"summary": {Input: "Summary"},
+	}
+
+	// missing golden file
+	r := New(fn, cases).Golden(dir).Run()
+	if r[0].Success || !strings.Contains(r[0].Diff, "not found") {
+		t.Fatalf("FAIL: missing golden file %v", r[0].string())
+	}
+
+	// create golden files
+	t.Setenv("TRIAL_UPDATE", "1")
+	New(fn, cases).Golden(dir).SubTest(t)
+	b, err := os.ReadFile(filepath.Join(dir, "TestTrial_Golden", "summary.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "{\n  \"Title\": \"Summary\",\n  \"Rows\": [\n    1,\n    2,\n    3\n  ]\n}\n"
+	if eq, diff := Equal(string(b), exp); !eq {
+		t.Errorf("FAIL: golden json %s", diff)
+	}
+
+	// compare with golden file
+	t.Setenv("TRIAL_UPDATE", "")
+	New(fn, cases).Golden(dir).Test(t)
+
+	// changed output
+	rows = []int{1, 2, 4}
+	tr := New(fn, cases).Golden(dir)
+	tr.suite = "TestTrial_Golden"
+	r = tr.Run()
+	if r[0].Success || !strings.Contains(r[0].Diff, "summary.golden") || !strings.Contains(r[0].Diff, "-") {
+		t.Errorf("FAIL: golden diff %v", r[0].string())
+	}
+}
+
+func TestMarshalGolden(t *testing.T) {
+	fn := func(v any) (string, error) {
+		b, err := marshalGolden(v)
+		return string(b), err
+	}
+	New(fn, Cases[any, string]{
+		"bytes":  {Input: []byte("raw\x00bytes"), Expected: "raw\x00bytes"},
+		"string": {Input: "line 1\nline 2", Expected: "line 1\nline 2"},
+		"map":    {Input: map[string]int{"b": 2, "a": 1}, Expected: "{\n  \"a\": 1,\n  \"b\": 2\n}\n"},
+		"func":   {Input: func() {}, ShouldErr: true},
+	}).SubTest(t)
+}
@@ -0,0 +1,79 @@
+package trial
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// leakSettle is how long goroutines started by a case have to stop
+// before they are reported as leaked
+var leakSettle = time.Second
+
+// leakMu runs the cases checked for leaks one at a time so the goroutines
+// of one case aren't blamed on another
+var leakMu sync.Mutex
+
+// DetectLeaks fails cases that leave goroutines running after the test function returns.
+// Goroutines have a short time to settle before the case fails with their stacks.
+// Cases checked for leaks run one at a time, even in parallel mode.
+func (t *Trial[In, Out]) DetectLeaks() *Trial[In, Out] {
+	t.detectLeaks = true
+	return t
+}
+
+// leaks waits for new goroutines that weren't running before to stop
+// and returns the stacks of the ones still running
+func leaks(before map[string]string) []string {
+	var leaked []string
+	deadline := time.Now().Add(leakSettle)
+	for wait := time.Millisecond; ; wait *= 2 {
+		leaked = leaked[:0]
+		for id, stack := range goroutines() {
+			if _, found := before[id]; !found && !ignoreGoroutine(stack) {
+				leaked = append(leaked, strings.TrimSpace(filterStack(stack)))
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(wait)
+	}
+	sort.Strings(leaked)
+	return leaked
+}
+
+// ignoreGoroutine is true for goroutines started by trial or the testing package
+// (the goroutine running the case and other subtests)
+func ignoreGoroutine(stack string) bool {
+	return strings.Contains(stack, ".runCase") ||
+		strings.Contains(stack, "testing.tRunner") ||
+		strings.Contains(stack, "testing.(*T).Run") ||
+		strings.Contains(stack, "testing.(*B).run")
+}
+
+// goroutines returns the stack of every running goroutine by its id
+func goroutines() map[string]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[string]string)
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		// goroutine 7 [running]:
+		header, _, _ := strings.Cut(g, "\n")
+		fields := strings.Fields(header)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		stacks[fields[1]] = g
+	}
+	return stacks
+}
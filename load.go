@@ -0,0 +1,281 @@
+package trial
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileCase is the format of a case stored in a file
+type fileCase[In any, Out any] struct {
+	Input       In     `json:"input" yaml:"input"`
+	Expected    Out    `json:"expected" yaml:"expected"`
+	ShouldErr   bool   `json:"shouldErr" yaml:"shouldErr"`
+	ExpectedErr string `json:"expectedErr" yaml:"expectedErr"`
+	ShouldPanic bool   `json:"shouldPanic" yaml:"shouldPanic"`
+}
+
+// LoadCases reads the named cases from a JSON (.json) or YAML (.yaml, .yml) file.
+// The file is a map of case names to cases, ExpectedErr is the expected error string.
+//
+//	"divide by zero":
+//	  input: [1, 0]
+//	  expectedErr: "divide by zero"
+func LoadCases[In any, Out any](path string) (Cases[In, Out], error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fCases map[string]fileCase[In, Out]
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&fCases)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		err = dec.Decode(&fCases)
+	default:
+		return nil, fmt.Errorf("unsupported case file %q (.json, .yaml, .yml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	cases := make(Cases[In, Out], len(fCases))
+	for name, c := range fCases {
+		tc := Case[In, Out]{
+			Input:       c.Input,
+			Expected:    c.Expected,
+			ShouldErr:   c.ShouldErr,
+			ShouldPanic: c.ShouldPanic,
+		}
+		if c.ExpectedErr != "" {
+			tc.ExpectedErr = errors.New(c.ExpectedErr)
+		}
+		cases[name] = tc
+	}
+	return cases, nil
+}
+
+// LoadTable reads cases from a CSV (.csv) or TSV (.tsv) file.
+// The first row is a header that maps each column to a field:
+//   - name: the name of the case (default "row N")
+//   - error: the expected error string
+//   - input: the input when In is not a struct
+//   - expected: the expected value when Out is not a struct
+//   - any other column is the name of a field in the In or Out struct (case-insensitive or csv tag).
+//     Use in.Field or out.Field when both structs have the same field.
+//
+// Values are converted like Input.Int, Input.Bool, Input.Float64, etc with
+// additional support for time.Duration and time.Time (RFC3339). Blank values are left empty.
+func LoadTable[In any, Out any](path string) (Cases[In, Out], error) {
+	var comma rune
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		comma = ','
+	case ".tsv":
+		comma = '\t'
+	default:
+		return nil, fmt.Errorf("unsupported table file %q (.csv, .tsv)", ext)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cases, err := readTable[In, Out](f, comma)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// column setter for a case from a table cell
+type column[In any, Out any] func(c *Case[In, Out], s string) error
+
+func readTable[In any, Out any](r io.Reader, comma rune) (Cases[In, Out], error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.TrimLeadingSpace = comma != '\t' // a blank first column is lost when trimming tabs
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	nameCol := -1
+	columns := make([]column[In, Out], len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if strings.EqualFold(h, "name") {
+			nameCol = i
+			continue
+		}
+		if columns[i], err = tableColumn[In, Out](h); err != nil {
+			return nil, err
+		}
+	}
+
+	cases := make(Cases[In, Out])
+	for row := 1; ; row++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("row %d", row)
+		if nameCol >= 0 && rec[nameCol] != "" {
+			name = rec[nameCol]
+		}
+		if _, found := cases[name]; found {
+			return nil, fmt.Errorf("row %d: duplicate case %q", row, name)
+		}
+		var c Case[In, Out]
+		for i, s := range rec {
+			if columns[i] == nil || s == "" {
+				continue
+			}
+			if err := columns[i](&c, s); err != nil {
+				return nil, fmt.Errorf("row %d %s: %w", row, header[i], err)
+			}
+		}
+		cases[name] = c
+	}
+	return cases, nil
+}
+
+// tableColumn finds the field in the case for the header name
+func tableColumn[In any, Out any](h string) (column[In, Out], error) {
+	lower := strings.ToLower(h)
+	if lower == "error" {
+		return func(c *Case[In, Out], s string) error {
+			c.ExpectedErr = errors.New(s)
+			return nil
+		}, nil
+	}
+	inType := reflect.TypeOf((*In)(nil)).Elem()
+	outType := reflect.TypeOf((*Out)(nil)).Elem()
+	if lower == "input" && inType.Kind() != reflect.Struct {
+		return func(c *Case[In, Out], s string) error {
+			return setValue(reflect.ValueOf(&c.Input).Elem(), s)
+		}, nil
+	}
+	if lower == "expected" && outType.Kind() != reflect.Struct {
+		return func(c *Case[In, Out], s string) error {
+			return setValue(reflect.ValueOf(&c.Expected).Elem(), s)
+		}, nil
+	}
+
+	field := h
+	inOnly := strings.HasPrefix(lower, "in.")
+	outOnly := strings.HasPrefix(lower, "out.")
+	if inOnly || outOnly {
+		field = h[strings.Index(h, ".")+1:]
+	}
+	inIdx := fieldIndex(inType, field)
+	outIdx := fieldIndex(outType, field)
+	switch {
+	case inIdx != nil && outIdx != nil && !inOnly && !outOnly:
+		return nil, fmt.Errorf("ambiguous column %q use in.%s or out.%s", h, field, field)
+	case inIdx != nil && !outOnly:
+		return func(c *Case[In, Out], s string) error {
+			return setValue(reflect.ValueOf(&c.Input).Elem().FieldByIndex(inIdx), s)
+		}, nil
+	case outIdx != nil && !inOnly:
+		return func(c *Case[In, Out], s string) error {
+			return setValue(reflect.ValueOf(&c.Expected).Elem().FieldByIndex(outIdx), s)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown column %q", h)
+}
+
+// fieldIndex of the exported field in a struct that matches the
+// name (case-insensitive) or csv tag
+func fieldIndex(t reflect.Type, name string) []int {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if tag := f.Tag.Get("csv"); tag == name || (tag == "" && strings.EqualFold(f.Name, name)) {
+			return f.Index
+		}
+	}
+	return nil
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// setValue converts s to the type of v using the same rules as Input
+func setValue(v reflect.Value, s string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	in := newInput(s)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(in.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(0)
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			i = int64(d)
+		} else {
+			i = int64(in.Int())
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("%s overflows %s", s, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := uint64(in.Uint())
+		if v.OverflowUint(u) {
+			return fmt.Errorf("%s overflows %s", s, v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(in.Float64())
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Struct:
+		if v.Type() != timeType {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
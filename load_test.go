@@ -0,0 +1,165 @@
+package trial
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCases(t *testing.T) {
+	type in struct {
+		A, B int
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"cases.json": `{
+  "divide": {"input": {"A": 10, "B": 2}, "expected": 5},
+  "divide by zero": {"input": {"A": 1, "B": 0}, "expectedErr": "divide by zero"},
+  "should error": {"input": {"A": 1}, "shouldErr": true},
+  "should panic": {"input": {"A": -1}, "shouldPanic": true}
+}`,
+		"cases.yaml": `
+divide:
+  input: {a: 10, b: 2}
+  expected: 5
+divide by zero:
+  input: {a: 1, b: 0}
+  expectedErr: divide by zero
+should error:
+  input: {a: 1}
+  shouldErr: true
+should panic:
+  input: {a: -1}
+  shouldPanic: true
+`,
+		"unknown.json": `{"typo": {"expectd": 5}}`,
+		"unknown.yml":  "typo:\n  expectd: 5\n",
+		"cases.txt":    "",
+	}
+	for name, s := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// errors are compared by their string
+	expected := map[string]fileCase[in, int]{
+		"divide":         {Input: in{A: 10, B: 2}, Expected: 5},
+		"divide by zero": {Input: in{A: 1, B: 0}, ExpectedErr: "divide by zero"},
+		"should error":   {Input: in{A: 1}, ShouldErr: true},
+		"should panic":   {Input: in{A: -1}, ShouldPanic: true},
+	}
+
+	fn := func(file string) (map[string]fileCase[in, int], error) {
+		cases, err := LoadCases[in, int](filepath.Join(dir, file))
+		result := make(map[string]fileCase[in, int])
+		for name, c := range cases {
+			fc := fileCase[in, int]{
+				Input:       c.Input,
+				Expected:    c.Expected,
+				ShouldErr:   c.ShouldErr,
+				ShouldPanic: c.ShouldPanic,
+			}
+			if c.ExpectedErr != nil {
+				fc.ExpectedErr = c.ExpectedErr.Error()
+			}
+			result[name] = fc
+		}
+		return result, err
+	}
+	New(fn, Cases[string, map[string]fileCase[in, int]]{
+		"json":             {Input: "cases.json", Expected: expected},
+		"yaml":             {Input: "cases.yaml", Expected: expected},
+		"json typo":        {Input: "unknown.json", ExpectedErr: errors.New(`unknown field "expectd"`)},
+		"yaml typo":        {Input: "unknown.yml", ExpectedErr: errors.New("field expectd not found")},
+		"unsupported type": {Input: "cases.txt", ExpectedErr: errors.New(`unsupported case file ".txt"`)},
+		"missing file":     {Input: "missing.json", ExpectedErr: ErrIs(os.ErrNotExist)},
+	}).SubTest(t)
+}
+
+func TestLoadTable(t *testing.T) {
+	type quote struct {
+		Qty      int
+		Price    float64 `csv:"unit price"`
+		Member   bool
+		Coupon   *string
+		Duration time.Duration
+		Name     string
+		Currency string
+	}
+	type total struct {
+		Total    float64
+		Name     string
+		Currency string
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"quotes.csv": "name,qty,unit price,member,coupon,duration,out.total,in.name,error\n" +
+			"single,1,9.99,false,,1h,9.99,bob,\n" +
+			"bulk,10,9.99,TRUE,SAVE10,,89.91,,\n" +
+			"invalid,-1,9.99,false,,,,,invalid quantity\n",
+		"quotes.tsv": "name\tqty\tout.total\n" +
+			"single\t1\t9.99\n" +
+			"\t2\t19.98\n",
+		"ambiguous.csv": "name\n" + "a,b\n",
+		"unknown.csv":   "name,discount\n" + "a,1\n",
+		"same.csv":      "qty,currency,total\n" + "1,USD,1\n",
+		"bad int.csv":   "qty\n" + "1.5\n",
+		"cases.txt":     "",
+	}
+	for name, s := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fn := func(file string) (map[string]fileCase[quote, total], error) {
+		cases, err := LoadTable[quote, total](filepath.Join(dir, file))
+		result := make(map[string]fileCase[quote, total])
+		for name, c := range cases {
+			fc := fileCase[quote, total]{Input: c.Input, Expected: c.Expected}
+			if c.ExpectedErr != nil {
+				fc.ExpectedErr = c.ExpectedErr.Error()
+			}
+			result[name] = fc
+		}
+		return result, err
+	}
+	New(fn, Cases[string, map[string]fileCase[quote, total]]{
+		"csv": {
+			Input: "quotes.csv",
+			Expected: map[string]fileCase[quote, total]{
+				"single":  {Input: quote{Qty: 1, Price: 9.99, Duration: time.Hour, Name: "bob"}, Expected: total{Total: 9.99}},
+				"bulk":    {Input: quote{Qty: 10, Price: 9.99, Member: true, Coupon: Pointer("SAVE10")}, Expected: total{Total: 89.91}},
+				"invalid": {Input: quote{Qty: -1, Price: 9.99}, ExpectedErr: "invalid quantity"},
+			},
+		},
+		"tsv": {
+			Input: "quotes.tsv",
+			Expected: map[string]fileCase[quote, total]{
+				"single": {Input: quote{Qty: 1}, Expected: total{Total: 9.99}},
+				"row 2":  {Input: quote{Qty: 2}, Expected: total{Total: 19.98}},
+			},
+		},
+		"wrong number of fields": {Input: "ambiguous.csv", ExpectedErr: errors.New("wrong number of fields")},
+		"unknown column":         {Input: "unknown.csv", ExpectedErr: errors.New(`unknown column "discount"`)},
+		"ambiguous column":       {Input: "same.csv", ExpectedErr: errors.New(`ambiguous column "currency" use in.currency or out.currency`)},
+		"invalid int":            {Input: "bad int.csv", ExpectedErr: errors.New("row 1 qty: invalid int 1.5")},
+		"unsupported type":       {Input: "cases.txt", ExpectedErr: errors.New(`unsupported table file ".txt"`)},
+	}).SubTest(t)
+}
+
+func TestLoadTable_Primitives(t *testing.T) {
+	cases, err := readTable[int, string](strings.NewReader("input,expected\n1,one\n2,two\n"), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := Cases[int, string]{
+		"row 1": {Input: 1, Expected: "one"},
+		"row 2": {Input: 2, Expected: "two"},
+	}
+	if eq, diff := Equal(cases, exp); !eq {
+		t.Errorf("FAIL: %s", diff)
+	}
+}
@@ -0,0 +1,413 @@
+package trial
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Generator creates a random value.
+// size is a hint for how large the value should be (ints, lengths of strings, slices, etc)
+// and grows as more inputs are tested.
+type Generator[T any] func(r *rand.Rand, size int) T
+
+// TypeGen overrides how a type is generated when inputs are created with reflection.
+// see Gen
+type TypeGen struct {
+	typ reflect.Type
+	fn  func(r *rand.Rand, size int) reflect.Value
+}
+
+// Gen creates a TypeGen for the type T
+func Gen[T any](g Generator[T]) TypeGen {
+	return TypeGen{
+		typ: reflect.TypeOf((*T)(nil)).Elem(),
+		fn: func(r *rand.Rand, size int) reflect.Value {
+			return reflect.ValueOf(g(r, size))
+		},
+	}
+}
+
+// PropertyTrial checks that a property holds for randomly generated inputs
+type PropertyTrial[In any] struct {
+	gen     Generator[In]
+	gens    map[reflect.Type]TypeGen
+	prop    func(In) error
+	count   int
+	maxSize int
+	seed    int64
+	timeout time.Duration
+	shrinks int
+}
+
+// Property tests that prop returns no error for randomly generated inputs.
+// When gen is nil inputs are generated with reflection over In (bools, numbers,
+// strings, slices, arrays, maps, pointers and structs).
+// Failing inputs are shrunk to a minimal counterexample.
+func Property[In any](gen Generator[In], prop func(In) error) *PropertyTrial[In] {
+	return &PropertyTrial[In]{
+		gen:     gen,
+		gens:    make(map[reflect.Type]TypeGen),
+		prop:    prop,
+		count:   100,
+		maxSize: 100,
+		seed:    time.Now().UnixNano(),
+		shrinks: 1000,
+	}
+}
+
+// Count is the number of inputs to generate. default 100
+func (p *PropertyTrial[In]) Count(n int) *PropertyTrial[In] {
+	p.count = n
+	return p
+}
+
+// MaxSize is the largest size passed to the generators. default 100
+func (p *PropertyTrial[In]) MaxSize(n int) *PropertyTrial[In] {
+	p.maxSize = n
+	return p
+}
+
+// Seed for the random inputs. The seed is logged so a failure can be reproduced.
+func (p *PropertyTrial[In]) Seed(seed int64) *PropertyTrial[In] {
+	p.seed = seed
+	return p
+}
+
+// Timeout for each input to finish in
+func (p *PropertyTrial[In]) Timeout(d time.Duration) *PropertyTrial[In] {
+	p.timeout = d
+	return p
+}
+
+// With overrides how types are generated with reflection
+func (p *PropertyTrial[In]) With(gens ...TypeGen) *PropertyTrial[In] {
+	for _, g := range gens {
+		p.gens[g.typ] = g
+	}
+	return p
+}
+
+// Test the property and fail with the smallest input found that breaks it
+func (p *PropertyTrial[In]) Test(tst testing.TB) {
+	if h, ok := tst.(tHelper); ok {
+		h.Helper()
+	}
+	tst.Logf("property seed: %d", p.seed)
+	r := p.Run()
+	if r.Success {
+		tst.Logf("PASS: %d inputs", r.Count)
+		return
+	}
+	tst.Errorf("FAIL: property failed after %d inputs (seed %d)\ninput: %+v\nshrunk (%d steps): %+v\n%s",
+		r.Count, p.seed, r.Original, r.Shrinks, r.Input, r.Message)
+}
+
+// PropertyResult is the outcome of a property trial
+type PropertyResult[In any] struct {
+	Success  bool
+	Count    int    // number of inputs tested
+	Original In     // the first input that failed
+	Input    In     // the smallest input found that fails
+	Shrinks  int    // number of times the failing input was shrunk
+	Message  string // the failure of the smallest input
+}
+
+// Run generates inputs until the property fails or count is reached
+func (p *PropertyTrial[In]) Run() PropertyResult[In] {
+	r := rand.New(rand.NewSource(p.seed))
+	t := New(func(in In) (struct{}, error) {
+		return struct{}{}, p.prop(in)
+	}, nil).Timeout(p.timeout)
+
+	for i := 0; i < p.count; i++ {
+		size := 1 + i*p.maxSize/p.count
+		in := p.generate(r, size)
+		res := t.testCase(fmt.Sprintf("input %d", i), Case[In, struct{}]{Input: in})
+		if res.Success {
+			continue
+		}
+		small, msg, shrinks := p.shrink(t, in, res.Message)
+		return PropertyResult[In]{
+			Count:    i + 1,
+			Original: in,
+			Input:    small,
+			Shrinks:  shrinks,
+			Message:  msg,
+		}
+	}
+	return PropertyResult[In]{Success: true, Count: p.count}
+}
+
+func (p *PropertyTrial[In]) generate(r *rand.Rand, size int) In {
+	if p.gen != nil {
+		return p.gen(r, size)
+	}
+	var in In
+	v := reflect.ValueOf(&in).Elem()
+	v.Set(p.random(v.Type(), r, size))
+	return in
+}
+
+// shrink repeatedly replaces the input with a smaller candidate that still fails
+func (p *PropertyTrial[In]) shrink(t *Trial[In, struct{}], in In, msg string) (In, string, int) {
+	steps := 0
+	v := reflect.ValueOf(&in).Elem()
+	for steps < p.shrinks {
+		shrunk := false
+		for _, c := range shrinkValue(v) {
+			candidate := c.Interface().(In)
+			res := t.testCase("shrink", Case[In, struct{}]{Input: candidate})
+			if !res.Success {
+				in, msg = candidate, res.Message
+				v = reflect.ValueOf(&in).Elem()
+				shrunk = true
+				steps++
+				break
+			}
+		}
+		if !shrunk {
+			break
+		}
+	}
+	return in, msg, steps
+}
+
+// random creates a value of type t using reflection
+func (p *PropertyTrial[In]) random(t reflect.Type, r *rand.Rand, size int) reflect.Value {
+	if g, found := p.gens[t]; found {
+		return g.fn(r, size).Convert(t)
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := int64(r.Intn(2*size+1) - size)
+		for v.OverflowInt(i) {
+			i /= 2
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := uint64(r.Intn(size + 1))
+		for v.OverflowUint(u) {
+			u /= 2
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(r.NormFloat64() * float64(size))
+	case reflect.String:
+		runes := make([]rune, r.Intn(size+1))
+		for i := range runes {
+			runes[i] = rune(' ' + r.Intn('~'-' '+1)) // printable ascii
+		}
+		v.SetString(string(runes))
+	case reflect.Slice:
+		// nested values are smaller to keep the total size reasonable
+		n := r.Intn(size + 1)
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := 0; i < n; i++ {
+			v.Index(i).Set(p.random(t.Elem(), r, size/2))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(p.random(t.Elem(), r, size/2))
+		}
+	case reflect.Map:
+		n := r.Intn(size + 1)
+		v.Set(reflect.MakeMapWithSize(t, n))
+		for i := 0; i < n; i++ {
+			v.SetMapIndex(p.random(t.Key(), r, size/2), p.random(t.Elem(), r, size/2))
+		}
+	case reflect.Pointer:
+		if r.Intn(size+1) > 0 {
+			e := reflect.New(t.Elem())
+			e.Elem().Set(p.random(t.Elem(), r, size/2))
+			v.Set(e)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				f.Set(p.random(f.Type(), r, size))
+			}
+		}
+	}
+	// interfaces, funcs and chans are left empty
+	return v
+}
+
+// shrinkValue returns smaller candidates of v, the smallest first
+func shrinkValue(v reflect.Value) []reflect.Value {
+	t := v.Type()
+	candidates := make([]reflect.Value, 0)
+	add := func(fn func(c reflect.Value)) {
+		c := reflect.New(t).Elem()
+		fn(c)
+		candidates = append(candidates, c)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			add(func(c reflect.Value) { c.SetBool(false) })
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i == 0 {
+			break
+		}
+		add(func(c reflect.Value) { c.SetInt(0) })
+		if i/2 != 0 {
+			add(func(c reflect.Value) { c.SetInt(i / 2) })
+		}
+		if i < 0 {
+			add(func(c reflect.Value) { c.SetInt(i + 1) })
+		} else {
+			add(func(c reflect.Value) { c.SetInt(i - 1) })
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u == 0 {
+			break
+		}
+		add(func(c reflect.Value) { c.SetUint(0) })
+		if u/2 != 0 {
+			add(func(c reflect.Value) { c.SetUint(u / 2) })
+		}
+		add(func(c reflect.Value) { c.SetUint(u - 1) })
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 || math.IsNaN(f) {
+			break
+		}
+		add(func(c reflect.Value) { c.SetFloat(0) })
+		if math.Trunc(f) != f {
+			add(func(c reflect.Value) { c.SetFloat(math.Trunc(f)) })
+		}
+		if math.Abs(f) > 1 {
+			add(func(c reflect.Value) { c.SetFloat(f / 2) })
+		}
+	case reflect.String:
+		s := []rune(v.String())
+		if len(s) == 0 {
+			break
+		}
+		add(func(c reflect.Value) { c.SetString("") })
+		add(func(c reflect.Value) { c.SetString(string(s[:len(s)/2])) })
+		add(func(c reflect.Value) { c.SetString(string(s[len(s)/2:])) })
+		for i := range s {
+			i := i
+			add(func(c reflect.Value) { c.SetString(string(s[:i]) + string(s[i+1:])) })
+		}
+	case reflect.Slice:
+		n := v.Len()
+		if v.IsNil() {
+			break
+		}
+		add(func(c reflect.Value) { c.Set(reflect.MakeSlice(t, 0, 0)) })
+		if n > 1 {
+			add(func(c reflect.Value) { c.Set(copySlice(v, 0, n/2)) })
+			add(func(c reflect.Value) { c.Set(copySlice(v, n/2, n)) })
+		}
+		for i := 0; i < n; i++ {
+			i := i
+			add(func(c reflect.Value) {
+				c.Set(reflect.AppendSlice(copySlice(v, 0, i), v.Slice(i+1, n)))
+			})
+		}
+		for i := 0; i < n; i++ {
+			for _, e := range shrinkValue(v.Index(i)) {
+				i, e := i, e
+				add(func(c reflect.Value) {
+					c.Set(copySlice(v, 0, n))
+					c.Index(i).Set(e)
+				})
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			for _, e := range shrinkValue(v.Index(i)) {
+				i, e := i, e
+				add(func(c reflect.Value) {
+					c.Set(v)
+					c.Index(i).Set(e)
+				})
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() || v.Len() == 0 {
+			break
+		}
+		add(func(c reflect.Value) { c.Set(reflect.MakeMap(t)) })
+		// sorted so shrinking is repeatable for a seed
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			key := key
+			add(func(c reflect.Value) {
+				c.Set(copyMap(v))
+				c.SetMapIndex(key, reflect.Value{})
+			})
+		}
+		for _, key := range keys {
+			for _, e := range shrinkValue(v.MapIndex(key)) {
+				key, e := key, e
+				add(func(c reflect.Value) {
+					c.Set(copyMap(v))
+					c.SetMapIndex(key, e)
+				})
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			break
+		}
+		add(func(c reflect.Value) {}) // nil
+		for _, e := range shrinkValue(v.Elem()) {
+			e := e
+			add(func(c reflect.Value) {
+				p := reflect.New(t.Elem())
+				p.Elem().Set(e)
+				c.Set(p)
+			})
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanInterface() {
+				continue
+			}
+			for _, e := range shrinkValue(v.Field(i)) {
+				i, e := i, e
+				add(func(c reflect.Value) {
+					c.Set(v)
+					if c.Field(i).CanSet() {
+						c.Field(i).Set(e)
+					}
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// copySlice returns a new slice with the elements of v[i:j]
+func copySlice(v reflect.Value, i, j int) reflect.Value {
+	c := reflect.MakeSlice(v.Type(), j-i, j-i)
+	reflect.Copy(c, v.Slice(i, j))
+	return c
+}
+
+// copyMap returns a shallow copy of the map
+func copyMap(v reflect.Value) reflect.Value {
+	c := reflect.MakeMapWithSize(v.Type(), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		c.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return c
+}
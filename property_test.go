@@ -0,0 +1,113 @@
+package trial
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestProperty(t *testing.T) {
+	// reversing a slice twice gives the original slice
+	Property(nil, func(in []int) error {
+		r := make([]int, len(in))
+		for i, v := range in {
+			r[len(in)-1-i] = v
+		}
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		if eq, diff := Equal(r, in); !eq {
+			return errors.New(diff)
+		}
+		return nil
+	}).Seed(1).Test(t)
+
+	// user generator
+	Property(func(r *rand.Rand, size int) int { return r.Intn(size+1) * 2 }, func(i int) error {
+		if i%2 != 0 {
+			return fmt.Errorf("%d is odd", i)
+		}
+		return nil
+	}).Seed(1).Test(t)
+}
+
+func TestProperty_Shrink(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+		Tags []string
+	}
+	type input struct {
+		prop func() PropertyResult[any]
+	}
+	fn := func(in input) (PropertyResult[any], error) {
+		return in.prop(), nil
+	}
+	cases := Cases[input, PropertyResult[any]]{
+		"slice of ints": {
+			Input: input{func() PropertyResult[any] {
+				return anyResult(Property(nil, func(in []int) error {
+					for _, v := range in {
+						if v >= 10 {
+							return fmt.Errorf("%d is too large", v)
+						}
+					}
+					return nil
+				}).Seed(1).Run())
+			}},
+			Expected: PropertyResult[any]{Input: []int{10}},
+		},
+		"struct field": {
+			Input: input{func() PropertyResult[any] {
+				return anyResult(Property(nil, func(u user) error {
+					if u.Age > 5 {
+						return errors.New("too old")
+					}
+					return nil
+				}).Seed(1).Run())
+			}},
+			Expected: PropertyResult[any]{Input: user{Age: 6, Tags: []string{}}},
+		},
+		"panic": {
+			Input: input{func() PropertyResult[any] {
+				return anyResult(Property(nil, func(in []int) error {
+					if len(in) > 1 {
+						panic("too many values")
+					}
+					return nil
+				}).Seed(1).Run())
+			}},
+			Expected: PropertyResult[any]{Input: []int{0, 0}},
+		},
+		"type generator": {
+			Input: input{func() PropertyResult[any] {
+				return anyResult(Property(nil, func(u user) error {
+					if !strings.HasPrefix(u.Name, "user-") {
+						return errors.New("invalid name")
+					}
+					return nil
+				}).With(Gen(func(r *rand.Rand, size int) string {
+					return fmt.Sprintf("user-%d", r.Intn(size+1))
+				})).Seed(1).Run())
+			}},
+			Expected: PropertyResult[any]{Success: true, Count: 100},
+		},
+	}
+	New(fn, cases).Comparer(EqualOpt(IgnoreFields("Count", "Original", "Shrinks", "Message"))).SubTest(t)
+}
+
+// anyResult converts the typed result for comparison
+func anyResult[In any](r PropertyResult[In]) PropertyResult[any] {
+	if r.Success {
+		return PropertyResult[any]{Success: true, Count: r.Count}
+	}
+	return PropertyResult[any]{
+		Count:    r.Count,
+		Original: r.Original,
+		Input:    r.Input,
+		Shrinks:  r.Shrinks,
+		Message:  r.Message,
+	}
+}
@@ -0,0 +1,270 @@
+package trial
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Format of a trial report
+type Format int
+
+const (
+	JUnit     Format = iota + 1 // JUnit XML, a testsuite per trial
+	JSONLines                   // JSON Lines, an object per case
+)
+
+// reportEnv enables reports for all trials without code changes.
+// format:dir -> TRIAL_REPORT=junit:./reports
+// each trial writes to dir/<TestName>.xml (junit) or dir/<TestName>.jsonl (json),
+// replacing the file from a previous run. Additional trials in the same test
+// write to <TestName>_2, <TestName>_3, ...
+const reportEnv = "TRIAL_REPORT"
+
+type reporter struct {
+	w      io.Writer
+	format Format
+}
+
+// Report writes the results of each case to w in the format given
+// after Test or SubTest has finished.
+// Reports can also be enabled with the TRIAL_REPORT environment variable
+// ex: TRIAL_REPORT=junit:./reports or TRIAL_REPORT=json:./reports
+func (t *Trial[In, Out]) Report(w io.Writer, format Format) *Trial[In, Out] {
+	t.reporters = append(t.reporters, reporter{w: w, format: format})
+	return t
+}
+
+// reporting is true when there is a report to write
+func (t *Trial[In, Out]) reporting() bool {
+	return len(t.reporters) > 0 || os.Getenv(reportEnv) != ""
+}
+
+// report writes the results to all configured reporters and
+// the report set by the environment variable
+func (t *Trial[In, Out]) report(tst testing.TB, results []Result[Out]) error {
+	suite := tst.Name()
+	for _, r := range t.reporters {
+		if err := WriteReport(r.w, r.format, suite, results); err != nil {
+			return err
+		}
+	}
+	env := os.Getenv(reportEnv)
+	if env == "" {
+		return nil
+	}
+	format, dir, err := parseReportEnv(env)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	ext := ".xml"
+	if format == JSONLines {
+		ext = ".jsonl"
+	}
+	f, err := os.Create(filepath.Join(dir, reportName(tst)+ext))
+	if err != nil {
+		return err
+	}
+	if err := WriteReport(f, format, suite, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reportNames counts the reports written by each test
+var reportNames = struct {
+	sync.Mutex
+	count map[any]int
+}{count: make(map[any]int)}
+
+// reportName is the file name (without extension) for the next report of a test.
+// Each trial gets its own file so a test that runs more than one trial
+// writes <TestName>, <TestName>_2, <TestName>_3, ...
+func reportName(tst testing.TB) string {
+	name := fileName(tst.Name())
+	var key any = tst
+	if !reflect.TypeOf(tst).Comparable() {
+		key = name
+	}
+	reportNames.Lock()
+	defer reportNames.Unlock()
+	reportNames.count[key]++
+	if n := reportNames.count[key]; n > 1 {
+		return fmt.Sprintf("%s_%d", name, n)
+	}
+	return name
+}
+
+// parseReportEnv splits the TRIAL_REPORT value into the format and directory
+func parseReportEnv(s string) (Format, string, error) {
+	name, dir, _ := strings.Cut(s, ":")
+	if dir == "" {
+		dir = "."
+	}
+	switch strings.ToLower(name) {
+	case "junit", "xml":
+		return JUnit, dir, nil
+	case "json", "jsonl":
+		return JSONLines, dir, nil
+	}
+	return 0, "", fmt.Errorf("invalid %s format %q (junit|json)", reportEnv, name)
+}
+
+var unsafeChars = regexp.MustCompile(`[^\w.-]+`)
+
+// fileName converts a test name into a safe file name
+func fileName(name string) string {
+	if name == "" {
+		return "trial"
+	}
+	return unsafeChars.ReplaceAllString(name, "_")
+}
+
+// WriteReport writes the results of a trial to w in the format given.
+// suite is the name of the group of results, generally the test name.
+func WriteReport[Out any](w io.Writer, format Format, suite string, results []Result[Out]) error {
+	switch format {
+	case JUnit:
+		return writeJUnit(w, suite, results)
+	case JSONLines:
+		return writeJSONLines(w, suite, results)
+	}
+	return fmt.Errorf("unknown report format %d", format)
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+func writeJUnit[Out any](w io.Writer, suite string, results []Result[Out]) error {
+	s := junitSuite{Name: suite, Tests: len(results)}
+	var total time.Duration
+	for _, r := range results {
+		total += r.Duration
+		c := junitCase{Name: r.Name, ClassName: suite, Time: seconds(r.Duration)}
+		switch r.status() {
+		case StatusSkip:
+			s.Skipped++
+			c.Skipped = &junitMessage{Message: r.Message}
+		case StatusPanic:
+			s.Errors++
+			c.Error = &junitMessage{Message: fmt.Sprint(r.Panic), Type: "panic", Body: r.Stack}
+		case StatusTimeout:
+			s.Errors++
+			c.Error = &junitMessage{Message: r.Message, Type: "timeout"}
+		case StatusFail:
+			s.Failures++
+			body := r.Diff
+			if r.Stack != "" {
+				body += "\n" + r.Stack
+			}
+			c.Failure = &junitMessage{Message: r.Message, Body: body}
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	s.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type jsonCase struct {
+	Suite    string          `json:"suite"`
+	Name     string          `json:"name"`
+	Status   Status          `json:"status"`
+	Duration float64         `json:"duration"` // seconds
+	Input    json.RawMessage `json:"input,omitempty"`
+	Output   json.RawMessage `json:"output,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Panic    string          `json:"panic,omitempty"`
+	Stack    string          `json:"stack,omitempty"`
+	Diff     string          `json:"diff,omitempty"`
+	Message  string          `json:"message"`
+}
+
+func writeJSONLines[Out any](w io.Writer, suite string, results []Result[Out]) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		c := jsonCase{
+			Suite:    suite,
+			Name:     r.Name,
+			Status:   r.status(),
+			Duration: r.Duration.Seconds(),
+			Input:    rawJSON(r.Input),
+			Output:   rawJSON(r.Output),
+			Stack:    r.Stack,
+			Diff:     r.Diff,
+			Message:  r.Message,
+		}
+		if r.Err != nil {
+			c.Error = r.Err.Error()
+		}
+		if r.Panic != nil {
+			c.Panic = fmt.Sprint(r.Panic)
+		}
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawJSON encodes v as json or as a json string
+// for values that can't be encoded (funcs, chans, etc)
+func rawJSON(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", v))
+	}
+	return b
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,147 @@
+package trial
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testResults() []Result[int] {
+	return []Result[int]{
+		{Name: "pass", Input: 1, Output: 2, Success: true, Message: `PASS: "pass"`, Duration: time.Millisecond},
+		{Name: "fail", Input: 2, Output: 3, Message: `FAIL: "fail"`, Diff: "-: 4\n+: 3", Duration: 2 * time.Millisecond},
+		{Name: "panic", Input: 3, Message: `PANIC: "panic" oops`, Panic: "oops", Stack: "main.go:10", panicked: true},
+		{Name: "timeout", Input: 4, Message: `FAIL: "timeout" timeout after 1s`, TimedOut: true, Duration: time.Second},
+		{Name: "skip", Input: 5, Message: `SKIP: "skip" later`, Skipped: true},
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	fn := func(f Format) (string, error) {
+		var b bytes.Buffer
+		err := WriteReport(&b, f, "TestSuite", testResults())
+		return b.String(), err
+	}
+	cases := Cases[Format, string]{
+		"junit": {
+			Input: JUnit,
+			Expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="TestSuite" tests="5" failures="1" errors="2" skipped="1" time="1.003">
+  <testcase name="pass" classname="TestSuite" time="0.001"></testcase>
+  <testcase name="fail" classname="TestSuite" time="0.002">
+    <failure message="FAIL: &#34;fail&#34;">-: 4&#xA;+: 3</failure>
+  </testcase>
+  <testcase name="panic" classname="TestSuite" time="0.000">
+    <error message="oops" type="panic">main.go:10</error>
+  </testcase>
+  <testcase name="timeout" classname="TestSuite" time="1.000">
+    <error message="FAIL: &#34;timeout&#34; timeout after 1s" type="timeout"></error>
+  </testcase>
+  <testcase name="skip" classname="TestSuite" time="0.000">
+    <skipped message="SKIP: &#34;skip&#34; later"></skipped>
+  </testcase>
+</testsuite>
+`,
+		},
+		"json lines": {
+			Input: JSONLines,
+			Expected: `{"suite":"TestSuite","name":"pass","status":"pass","duration":0.001,"input":1,"output":2,"message":"PASS: \"pass\""}
+{"suite":"TestSuite","name":"fail","status":"fail","duration":0.002,"input":2,"output":3,"diff":"-: 4\n+: 3","message":"FAIL: \"fail\""}
+{"suite":"TestSuite","name":"panic","status":"panic","duration":0,"input":3,"output":0,"panic":"oops","stack":"main.go:10","message":"PANIC: \"panic\" oops"}
+{"suite":"TestSuite","name":"timeout","status":"timeout","duration":1,"input":4,"output":0,"message":"FAIL: \"timeout\" timeout after 1s"}
+{"suite":"TestSuite","name":"skip","status":"skip","duration":0,"input":5,"output":0,"message":"SKIP: \"skip\" later"}
+`,
+		},
+		"unknown format": {
+			Input:       Format(99),
+			ExpectedErr: ErrMatch("unknown report format 99"),
+		},
+	}
+	New(fn, cases).SubTest(t)
+}
+
+func TestReport_Env(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(reportEnv, "json:"+dir)
+
+	var b bytes.Buffer
+	fn := func(i int) (int, error) { return i, nil }
+	t.Run("sub/test", func(t *testing.T) {
+		New(fn, Cases[int, int]{
+			"a": {Input: 1, Expected: 1},
+			"b": {Input: 2, Skip: "not ready"},
+		}).Report(&b, JUnit).SubTest(t)
+	})
+
+	if !strings.Contains(b.String(), `<testsuite name="TestReport_Env/sub/test" tests="2" failures="0" errors="0" skipped="1"`) {
+		t.Errorf("FAIL: junit writer %s", b.String())
+	}
+	f, err := os.ReadFile(filepath.Join(dir, "TestReport_Env_sub_test.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(f)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"status":"pass"`) || !strings.Contains(lines[1], `"status":"skip"`) {
+		t.Errorf("FAIL: json file %s", f)
+	}
+}
+
+func TestReport_EnvMultipleTrials(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(reportEnv, "junit:"+dir)
+	// results from a previous run are replaced
+	stale := filepath.Join(dir, "TestReport_EnvMultipleTrials_multi.xml")
+	if err := os.WriteFile(stale, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fn := func(i int) (int, error) { return i, nil }
+	t.Run("multi", func(t *testing.T) {
+		New(fn, Cases[int, int]{"a": {Input: 1, Expected: 1}}).Test(t)
+		New(fn, Cases[int, int]{"b": {Input: 2, Expected: 2}}).Test(t)
+	})
+
+	for file, name := range map[string]string{
+		"TestReport_EnvMultipleTrials_multi.xml":   `<testcase name="a"`,
+		"TestReport_EnvMultipleTrials_multi_2.xml": `<testcase name="b"`,
+	} {
+		f, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(f), `tests="1"`) || !strings.Contains(string(f), name) {
+			t.Errorf("FAIL: %s %s", file, f)
+		}
+	}
+}
+
+func TestReport_Filtered(t *testing.T) {
+	fn := func(i int) (int, error) { return i, nil }
+	if os.Getenv("TRIAL_FILTERED") != "" {
+		New(fn, Cases[int, int]{
+			"a": {Input: 1, Expected: 1},
+			"b": {Input: 2, Expected: 2},
+		}).SubTest(t)
+		return
+	}
+
+	// run only subtest a in a separate process
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestReport_Filtered$/^a$", "-test.count=1")
+	cmd.Env = append(os.Environ(), "TRIAL_FILTERED=1", reportEnv+"=json:"+dir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v %s", err, out)
+	}
+	f, err := os.ReadFile(filepath.Join(dir, "TestReport_Filtered.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(f)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"name":"a","status":"pass"`) {
+		t.Errorf("FAIL: filtered report %s", f)
+	}
+}
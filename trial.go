@@ -3,9 +3,15 @@ package trial
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"os"
 	"reflect"
+	"regexp"
+	"runtime"
 	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -23,11 +29,19 @@ type (
 	// "-" elements missing from actual
 	// "+" elements missing from expected
 	CompareFunc               func(actual, expected interface{}) (equal bool, differences string)
-	testFunc[In any, Out any] func(in In) (result Out, err error)
+	testFunc[In any, Out any] func(ctx context.Context, in In) (result Out, err error)
 )
 
+// grace is the default time a context aware function has to return after
+// its context is canceled
+const grace = 100 * time.Millisecond
+
 // Comparer interface is implemented by an object to check for equality
-// and show any differences found
+// and show any differences found.
+// Equal, EqualOpt and Contains use Equals when the actual value implements it.
+// Like cmp's rule for Equal methods, Equals must not call Equal or Contains
+// with values of its own type as that calls Equals again and never returns.
+// Convert to another type first (ex: Equal(string(a), string(b))).
 type Comparer interface {
 	Equals(interface{}) (bool, string)
 }
@@ -38,11 +52,56 @@ type Trial[In any, Out any] struct {
 	testFn  testFunc[In, Out]
 	equalFn CompareFunc
 	timeout time.Duration
+
+	// cancelable functions are told to stop when the timeout is reached
+	cancelable bool
+	grace      time.Duration
+
+	parallel bool
+	workers  int
+
+	skipValidation bool // don't check the output before benchmarking
+	detectLeaks    bool // fail cases that leave goroutines running
+
+	reporters []reporter
+	goldenDir string
+	suite     string // name of the test running the trial
+	color     ColorMode
+	formatter Formatter
+
+	ordered []string // names of cases in the order they were added
+	shuffle bool
+	seed    int64
+
+	beforeAll  func()
+	afterAll   func()
+	beforeEach func(name string, in *In)
+	afterEach  func(name string, r Result[Out])
 }
 
 // Cases made during the trial
 type Cases[In any, Out any] map[string]Case[In, Out]
 
+// OrderedCases is a collection of cases that are run in the order they were added.
+// see Trial.Ordered
+type OrderedCases[In any, Out any] struct {
+	names []string
+	cases Cases[In, Out]
+}
+
+// Add a case to the end of the list. Adding a name that already
+// exists replaces the case but keeps its original position.
+func (o *OrderedCases[In, Out]) Add(name string, c Case[In, Out]) *OrderedCases[In, Out] {
+	if o.cases == nil {
+		o.cases = make(Cases[In, Out])
+	}
+	if _, found := o.cases[name]; !found {
+		o.names = append(o.names, name)
+	}
+	o.cases[name] = c
+	return o
+}
+
 // Case made during the trial of your code
 type Case[In any, Out any] struct {
 	Input    In
@@ -52,20 +111,61 @@ type Case[In any, Out any] struct {
 	ShouldErr   bool  // is an error expected
 	ExpectedErr error // the error that was expected (nil is no error expected)
 	ShouldPanic bool  // is a panic expected
+
+	// the value the function should panic with (implies ShouldPanic)
+	// strings and errors are matched like ExpectedErr,
+	// all other values use the trial's comparer
+	ExpectedPanic any
+
+	Timeout time.Duration // overrides the trial timeout for this case
+	Skip    string        // skip the case with the reason given
+	Only    bool          // only run cases marked Only (fails in CI)
+
+	// budgets for a single call of the test function, zero is no limit.
+	// Allocations are measured with runtime.MemStats and include all goroutines
+	// so avoid other work (parallel cases) when checking them.
+	MaxAllocs   int           // max number of heap allocations (NoAllocs for none)
+	MaxBytes    int64         // max bytes allocated on the heap (NoAllocs for none)
+	MaxDuration time.Duration // max time in the test function (the case still runs to completion)
 }
 
+// NoAllocs is used with MaxAllocs or MaxBytes when a case must not allocate
+const NoAllocs = -1
+
+// measureMu keeps cases with allocation budgets from being measured at the same time
+var measureMu sync.Mutex
+
 func New[In any, Out any](fn func(In) (Out, error), cases map[string]Case[In, Out]) *Trial[In, Out] {
 	if cases == nil {
 		cases = make(map[string]Case[In, Out])
 	}
 
 	return &Trial[In, Out]{
-		cases:   cases,
-		testFn:  fn,
+		cases: cases,
+		testFn: func(_ context.Context, in In) (Out, error) {
+			return fn(in)
+		},
 		equalFn: Equal,
 	}
 }
 
+// NewCtx creates a trial for a context aware function.
+// The context is canceled when the case times out and the function
+// fails as a leak if it doesn't return within the grace period (see GracePeriod).
+func NewCtx[In any, Out any](fn func(context.Context, In) (Out, error), cases map[string]Case[In, Out]) *Trial[In, Out] {
+	if cases == nil {
+		cases = make(map[string]Case[In, Out])
+	}
+
+	return &Trial[In, Out]{
+		cases:      cases,
+		testFn:     fn,
+		equalFn:    Equal,
+		cancelable: true,
+		grace:      grace,
+	}
+}
+
 // EqualFn override the default comparison method used.
 // see ContainsFn(x, y interface{}) (bool, string)
 // deprecated
@@ -81,25 +181,141 @@ func (t *Trial[In, Out]) Comparer(fn CompareFunc) *Trial[In, Out] {
 	return t
 }
 
-// SubTest runs all cases as individual subtests
+// Parallel runs the cases concurrently.
+// SubTest marks each subtest as parallel and Test runs the cases with a pool of workers.
+// Results are still reported in case order.
+func (t *Trial[In, Out]) Parallel() *Trial[In, Out] {
+	t.parallel = true
+	return t
+}
+
+// Workers limits the number of cases that run at the same time in parallel mode.
+// It defaults to runtime.GOMAXPROCS and implies Parallel.
+func (t *Trial[In, Out]) Workers(n int) *Trial[In, Out] {
+	t.parallel = true
+	t.workers = n
+	return t
+}
+
+// Ordered replaces the trial's cases with the ordered cases and runs
+// them in the order they were added instead of sorted by name.
+func (t *Trial[In, Out]) Ordered(cases *OrderedCases[In, Out]) *Trial[In, Out] {
+	t.cases = make(Cases[In, Out])
+	t.ordered = make([]string, 0, len(cases.names))
+	for _, name := range cases.names {
+		t.cases[name] = cases.cases[name]
+		t.ordered = append(t.ordered, name)
+	}
+	return t
+}
+
+// Shuffle runs the cases in a random order. The seed is logged so an
+// order dependent failure can be reproduced by passing the same seed.
+// A seed of 0 uses the current time.
+func (t *Trial[In, Out]) Shuffle(seed int64) *Trial[In, Out] {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	t.shuffle = true
+	t.seed = seed
+	return t
+}
+
+// BeforeAll is called once before any of the cases are run
+func (t *Trial[In, Out]) BeforeAll(fn func()) *Trial[In, Out] {
+	t.beforeAll = fn
+	return t
+}
+
+// AfterAll is called once after all cases have finished.
+// With SubTest it runs as a cleanup of the parent test so parallel subtests are done.
+func (t *Trial[In, Out]) AfterAll(fn func()) *Trial[In, Out] {
+	t.afterAll = fn
+	return t
+}
+
+// BeforeEach is called before each case is run. The input may be modified
+// and is then passed to the test function.
+func (t *Trial[In, Out]) BeforeEach(fn func(name string, in *In)) *Trial[In, Out] {
+	t.beforeEach = fn
+	return t
+}
+
+// AfterEach is called with the result of each case.
+// It is always called, even when the test function panics or times out.
+func (t *Trial[In, Out]) AfterEach(fn func(name string, r Result[Out])) *Trial[In, Out] {
+	t.afterEach = fn
+	return t
+}
+
+// SubTest runs all cases as individual subtests.
+// A *testing.B runs the cases as sub-benchmarks (see Bench).
 func (t *Trial[In, Out]) SubTest(tst testing.TB) {
 	if h, ok := tst.(tHelper); ok {
 		h.Helper()
 	}
+	if b, ok := tst.(*testing.B); ok {
+		t.Bench(b)
+		return
+	}
 
-	for msg, test := range t.cases {
+	t.start(tst)
+	if t.afterAll != nil {
+		tst.Cleanup(t.afterAll)
+	}
+	var sem chan struct{}
+	if t.parallel {
+		sem = make(chan struct{}, t.limit())
+	}
+	names := t.names()
+	results := make([]Result[Out], len(names))
+	ran := make([]bool, len(names))
+	if t.reporting() {
+		// runs after all subtests (including parallel) have finished
+		tst.Cleanup(func() {
+			// subtests filtered out with -run are left out of the report
+			reported := make([]Result[Out], 0, len(results))
+			for i, r := range results {
+				if ran[i] {
+					reported = append(reported, r)
+				}
+			}
+			if err := t.report(tst, reported); err != nil {
+				tst.Error("report: " + err.Error())
+			}
+		})
+	}
+	for i, msg := range names {
+		i, msg, test := i, msg, t.cases[msg]
 		tst.(*testing.T).Run(msg, func(tb *testing.T) {
 			tb.Helper()
+			ran[i] = true
+			if reason := t.skipReason(test); reason != "" {
+				results[i] = t.testCase(msg, test)
+				tb.Skip(reason)
+			}
+			if t.parallel {
+				tb.Parallel()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
 			r := t.testCase(msg, test)
+			results[i] = r
 			if !r.Success {
-				s := strings.Replace(r.Message, "\""+msg+"\"", "", 1)
-				s = strings.Replace(s, "FAIL:", "", 1)
-				tb.Error("\033[31m" + strings.TrimLeft(s, " \n") + "\033[39m")
+				tb.Error(t.format(r.status(), subMessage(msg, r.Message)))
 			}
 		})
 	}
 }
 
+// subMessage removes the case name and FAIL prefix from a message
+// as they are already shown by the subtest
+func subMessage(name, msg string) string {
+	s := strings.Replace(msg, "\""+name+"\"", "", 1)
+	s = strings.Replace(s, "FAIL:", "", 1)
+	return strings.TrimLeft(s, " \n")
+}
+
 // Timeout will make sure that a test case has finished
 // within the timeout or the test will fail.
 func (t *Trial[In, Out]) Timeout(d time.Duration) *Trial[In, Out] {
@@ -107,76 +323,340 @@ func (t *Trial[In, Out]) Timeout(d time.Duration) *Trial[In, Out] {
 	return t
 }
 
+// GracePeriod is how long a context aware function (see NewCtx) has
+// to return after a timeout cancels its context. default 100ms
+func (t *Trial[In, Out]) GracePeriod(d time.Duration) *Trial[In, Out] {
+	t.grace = d
+	return t
+}
+
 // Test all cases provided
 func (t *Trial[In, Out]) Test(tst testing.TB) {
 	if h, ok := tst.(tHelper); ok {
 		h.Helper()
 	}
-	for msg, test := range t.cases {
-		r := t.testCase(msg, test)
-		if r.Success {
-			tst.Log(r.Message)
+	t.start(tst)
+	if t.afterAll != nil {
+		defer t.afterAll()
+	}
+	results := t.run(t.names())
+	for _, r := range results {
+		if status := r.status(); status.Failed() {
+			tst.Error(t.format(status, r.Message))
 		} else {
-			tst.Error("\033[31m" + r.Message + "\033[39m")
+			tst.Log(t.format(status, r.Message))
 		}
 	}
+	if err := t.report(tst, results); err != nil {
+		tst.Error("report: " + err.Error())
+	}
 }
 
-func (t *Trial[In, Out]) testCase(msg string, test Case[In, Out]) result {
+// start is called before the cases are run to record the test name,
+// log the trial settings and call BeforeAll
+func (t *Trial[In, Out]) start(tst testing.TB) {
+	if h, ok := tst.(tHelper); ok {
+		h.Helper()
+	}
+	t.suite = tst.Name()
+	if t.shuffle {
+		tst.Logf("shuffle seed: %d", t.seed)
+	}
+	if t.focused() {
+		// make sure a focused trial isn't committed by accident
+		if os.Getenv("CI") != "" {
+			tst.Error("FAIL: cases marked Only are not allowed in CI")
+		} else {
+			tst.Log("WARNING: only running cases marked Only")
+		}
+	}
+	if t.beforeAll != nil {
+		t.beforeAll()
+	}
+}
+
+// focused is true if any case is marked as Only
+func (t *Trial[In, Out]) focused() bool {
+	for _, c := range t.cases {
+		if c.Only {
+			return true
+		}
+	}
+	return false
+}
+
+// skipReason returns why a case should be skipped or a blank string if it should run
+func (t *Trial[In, Out]) skipReason(test Case[In, Out]) string {
+	if test.Skip != "" {
+		return test.Skip
+	}
+	if !test.Only && t.focused() {
+		return "not marked Only"
+	}
+	return ""
+}
+
+// Run all cases without a testing.TB and return the results in case order.
+// This can be used to drive a trial from a custom harness.
+func (t *Trial[In, Out]) Run() []Result[Out] {
+	if t.beforeAll != nil {
+		t.beforeAll()
+	}
+	if t.afterAll != nil {
+		defer t.afterAll()
+	}
+	return t.run(t.names())
+}
+
+// names of all cases in the order they should run.
+// Cases are sorted by name unless they were added in order (see Ordered)
+// and then shuffled if a seed was given.
+func (t *Trial[In, Out]) names() []string {
+	names := make([]string, 0, len(t.cases))
+	if t.ordered != nil {
+		names = append(names, t.ordered...)
+	} else {
+		for name := range t.cases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if t.shuffle {
+		r := rand.New(rand.NewSource(t.seed))
+		r.Shuffle(len(names), func(i, j int) {
+			names[i], names[j] = names[j], names[i]
+		})
+	}
+	return names
+}
+
+// limit is the max number of cases to run at once
+func (t *Trial[In, Out]) limit() int {
+	if t.workers > 0 {
+		return t.workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// run the named cases and return the results in the same order as names.
+// In parallel mode the cases are spread across a pool of workers.
+func (t *Trial[In, Out]) run(names []string) []Result[Out] {
+	results := make([]Result[Out], len(names))
+	if !t.parallel {
+		for i, name := range names {
+			results[i] = t.testCase(name, t.cases[name])
+		}
+		return results
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < t.limit(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = t.testCase(names[i], t.cases[names[i]])
+			}
+		}()
+	}
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// testCase runs a single case wrapped by the BeforeEach and AfterEach hooks
+func (t *Trial[In, Out]) testCase(msg string, test Case[In, Out]) Result[Out] {
+	if reason := t.skipReason(test); reason != "" {
+		r := Result[Out]{Name: msg, Input: test.Input}
+		r.skip("SKIP: %q %s", msg, reason)
+		return r
+	}
+	if t.beforeEach != nil {
+		t.beforeEach(msg, &test.Input)
+	}
+	r := t.runCase(msg, test)
+	r.Name = msg
+	r.Input = test.Input
+	if t.afterEach != nil {
+		t.afterEach(msg, r)
+	}
+	return r
+}
+
+func (t *Trial[In, Out]) runCase(msg string, test Case[In, Out]) Result[Out] {
 	// setup
-	done := make(chan *result)
-	ctx := context.Background()
-	if t.timeout > time.Nanosecond {
-		ctx, _ = context.WithTimeout(context.Background(), t.timeout)
+	done := make(chan *Result[Out], 1)
+	timeout := t.timeout
+	if test.Timeout > 0 {
+		timeout = test.Timeout
+	}
+	measure := test.MaxAllocs != 0 || test.MaxBytes != 0
+	if measure {
+		// held by runCase rather than the test function so a call
+		// that times out doesn't block later measured cases
+		measureMu.Lock()
+		defer measureMu.Unlock()
 	}
+	var before map[string]string
+	if t.detectLeaks {
+		leakMu.Lock()
+		defer leakMu.Unlock()
+		before = goroutines()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > time.Nanosecond {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	}
+	defer cancel()
+	start := time.Now()
 	// run the test function
 	go func() {
-		r := &result{}
-		defer func() { // panic recovery and check
+		r := &Result[Out]{}
+		called := time.Now() // Duration only includes the test function
+		defer func() {       // panic recovery and check
 			rec := recover()
+			if r.Duration == 0 {
+				r.Duration = time.Since(called)
+			}
+			shouldPanic := test.ShouldPanic || test.ExpectedPanic != nil
 			r.panicCheck = rec != nil
-			if rec == nil && test.ShouldPanic {
+			if rec != nil {
+				r.Panic = rec
+				r.Stack = cleanStack()
+			}
+			if rec == nil && shouldPanic {
 				r.fail("FAIL: %q did not panic", msg)
 				r.panicCheck = true
-			} else if rec != nil && !test.ShouldPanic {
-				r.fail("PANIC: %q %v\n%s", msg, rec, cleanStack())
+			} else if rec != nil && !shouldPanic {
+				r.panicked = true
+				r.fail("PANIC: %q %v\n%s", msg, rec, r.Stack)
+			} else if rec != nil && test.ExpectedPanic != nil {
+				if ok, diff := t.isExpectedPanic(rec, test.ExpectedPanic); !ok {
+					r.Diff = diff
+					r.fail("FAIL: %q panic %q does not match expected %q\n%s\n%s",
+						msg, fmt.Sprint(rec), fmt.Sprint(test.ExpectedPanic), diff, strings.TrimSpace(r.Stack))
+				} else {
+					r.pass("PASS: %q", msg)
+				}
 			} else {
 				r.pass("PASS: %q", msg)
 			}
 			done <- r // send result to channel
 		}()
-		r.value, r.err = t.testFn(test.Input)
+		if measure {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			called = time.Now()
+			r.Output, r.Err = t.testFn(ctx, test.Input)
+			r.Duration = time.Since(called)
+			runtime.ReadMemStats(&after)
+			r.Allocs = after.Mallocs - before.Mallocs
+			r.Bytes = after.TotalAlloc - before.TotalAlloc
+			return
+		}
+		r.Output, r.Err = t.testFn(ctx, test.Input)
 	}()
-	result := &result{}
+	result := &Result[Out]{}
 	select {
 	case result = <-done:
 		if result.panicCheck {
 			return *result
 		}
 	case <-ctx.Done():
-		result.fail("FAIL: %q timeout after %s", msg, t.timeout.String())
+		result.TimedOut = true
+		if !t.cancelable {
+			result.Duration = time.Since(start)
+			result.fail("FAIL: %q timeout after %s", msg, timeout.String())
+			return *result
+		}
+		// the function was canceled, make sure it stops
+		select {
+		case <-done:
+			result.fail("FAIL: %q timeout after %s", msg, timeout.String())
+		case <-time.After(t.grace):
+			result.fail("FAIL: %q timeout after %s and did not return within %s of cancel (leaked)",
+				msg, timeout.String(), t.grace.String())
+		}
+		result.Duration = time.Since(start)
 		return *result
 	}
 
-	if (test.ShouldErr && result.err == nil) || (test.ExpectedErr != nil && result.err == nil) {
+	if (test.ShouldErr && result.Err == nil) || (test.ExpectedErr != nil && result.Err == nil) {
 		result.fail("FAIL: %q should error", msg)
-	} else if !test.ShouldErr && result.err != nil && test.ExpectedErr == nil {
-		result.fail("FAIL: %q unexpected error '%s'", msg, result.err.Error())
-	} else if test.ExpectedErr != nil && !isExpectedError(result.err, test.ExpectedErr) {
-		result.fail("FAIL: %q error %q does not match expected %q", msg, result.err, test.ExpectedErr)
+	} else if !test.ShouldErr && result.Err != nil && test.ExpectedErr == nil {
+		result.fail("FAIL: %q unexpected error '%s'", msg, result.Err.Error())
+	} else if test.ExpectedErr != nil {
+		if ok, why := isExpectedError(result.Err, test.ExpectedErr); !ok {
+			result.Diff = why
+			if why != "" {
+				why = "\n" + why
+			}
+			result.fail("FAIL: %q error %q does not match expected %q%s", msg, result.Err, test.ExpectedErr, why)
+		}
 	} else if !test.ShouldErr && test.ExpectedErr == nil {
-		if equal, diff := t.equalFn(result.value, test.Expected); !equal {
+		if equal, diff := t.compare(msg, result.Output, test.Expected); !equal {
+			result.Diff = diff
 			result.fail("FAIL: %q \n%s", msg, diff)
 		} else {
 			result.pass("PASS: %q", msg)
 		}
 	}
+	if result.Success {
+		if over := overBudget(test, *result); over != "" {
+			result.fail("FAIL: %q %s", msg, over)
+		}
+	}
+	if result.Success && t.detectLeaks {
+		if leaked := leaks(before); len(leaked) > 0 {
+			result.Stack = strings.Join(leaked, "\n")
+			result.fail("FAIL: %q leaked %d goroutine(s)\n%s", msg, len(leaked), result.Stack)
+		}
+	}
 	return *result
 }
 
+// overBudget describes the first budget of the case that was exceeded
+func overBudget[In, Out any](test Case[In, Out], r Result[Out]) string {
+	if test.MaxAllocs != 0 && r.Allocs > uint64(maxZero(test.MaxAllocs)) {
+		return fmt.Sprintf("%d allocs exceeds max of %d", r.Allocs, maxZero(test.MaxAllocs))
+	}
+	if test.MaxBytes != 0 && r.Bytes > uint64(maxZero(test.MaxBytes)) {
+		return fmt.Sprintf("%d bytes allocated exceeds max of %d", r.Bytes, maxZero(test.MaxBytes))
+	}
+	if test.MaxDuration > 0 && r.Duration > test.MaxDuration {
+		return fmt.Sprintf("took %s exceeds max duration of %s", r.Duration, test.MaxDuration)
+	}
+	return ""
+}
+
+// maxZero treats negative budgets (NoAllocs) as zero
+func maxZero[T int | int64](i T) T {
+	if i < 0 {
+		return 0
+	}
+	return i
+}
+
+// compare the actual output with the expected value or the golden file (see Golden)
+func (t *Trial[In, Out]) compare(name string, actual, expected Out) (bool, string) {
+	if t.goldenDir != "" {
+		return t.golden(name, actual)
+	}
+	return t.equalFn(actual, expected)
+}
+
 // cleanStack removes unhelpful lines from a panic stack track
-func cleanStack() (s string) {
-	for _, ln := range strings.Split(string(debug.Stack()), "\n") {
+func cleanStack() string {
+	return filterStack(string(debug.Stack()))
+}
+
+// filterStack removes the trial and runtime panic lines from a stack trace
+func filterStack(stack string) (s string) {
+	for _, ln := range strings.Split(stack, "\n") {
 		if !localTest && strings.Contains(ln, "/hydronica/trial") {
 			continue
 		}
@@ -191,48 +671,211 @@ func cleanStack() (s string) {
 	return s
 }
 
-func isExpectedError(actual, expected error) bool {
+// isExpectedPanic checks the recovered value against the expected panic.
+// A string is expected to be contained in the panic message and an error
+// is checked like ExpectedErr. All other values use the trial's comparer.
+func (t *Trial[In, Out]) isExpectedPanic(rec, expected any) (bool, string) {
+	recErr, ok := rec.(error)
+	if !ok {
+		recErr = fmt.Errorf("%v", rec)
+	}
+	switch exp := expected.(type) {
+	case string:
+		if strings.Contains(recErr.Error(), exp) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%q does not contain %q", recErr.Error(), exp)
+	case error:
+		return isExpectedError(recErr, exp)
+	}
+	return t.equalFn(rec, expected)
+}
+
+func isExpectedError(actual, expected error) (bool, string) {
 	if err, ok := expected.(errCheck); ok {
-		return reflect.TypeOf(actual) == reflect.TypeOf(err.err)
+		return err.match(actual)
 	}
-	return strings.Contains(actual.Error(), expected.Error())
+	return strings.Contains(actual.Error(), expected.Error()), ""
 }
 
+// errCheck is used with ExpectedErr to check the actual error with
+// a custom match instead of comparing the error strings.
+// match returns a description of why the error didn't match.
 type errCheck struct {
-	err error
+	desc  string
+	match func(actual error) (bool, string)
 }
 
 func (e errCheck) Error() string {
-	return e.err.Error()
+	return e.desc
 }
 
 // ErrType can be used with ExpectedErr to check
 // that the expected err is of a certain type
 func ErrType(err error) error {
-	return errCheck{err}
+	return errCheck{
+		desc: err.Error(),
+		match: func(actual error) (bool, string) {
+			if reflect.TypeOf(actual) == reflect.TypeOf(err) {
+				return true, ""
+			}
+			return false, fmt.Sprintf("type %T != %T", actual, err)
+		},
+	}
 }
 
-type result struct {
-	Success    bool
-	Message    string
-	value      interface{}
-	err        error
+// ErrIs can be used with ExpectedErr to check that the actual error
+// or any error it wraps is the target (see errors.Is)
+func ErrIs(target error) error {
+	canEqual := target == nil || reflect.TypeOf(target).Comparable()
+	return errCheck{
+		desc: fmt.Sprintf("is %v", target),
+		match: func(actual error) (bool, string) {
+			chain := errChain(actual)
+			for _, e := range chain {
+				if canEqual && e == target {
+					return true, ""
+				}
+				if x, ok := e.(interface{ Is(error) bool }); ok && x.Is(target) {
+					return true, ""
+				}
+			}
+			return false, searched(chain)
+		},
+	}
+}
+
+// ErrAs can be used with ExpectedErr to check that the actual error
+// or any error it wraps is of type T (see errors.As)
+func ErrAs[T error]() error {
+	return errCheck{
+		desc: fmt.Sprintf("as %v", reflect.TypeOf((*T)(nil)).Elem()),
+		match: func(actual error) (bool, string) {
+			chain := errChain(actual)
+			for _, e := range chain {
+				if _, ok := e.(T); ok {
+					return true, ""
+				}
+				var target T
+				if x, ok := e.(interface{ As(any) bool }); ok && x.As(&target) {
+					return true, ""
+				}
+			}
+			return false, searched(chain)
+		},
+	}
+}
+
+// ErrMatch can be used with ExpectedErr to check that the error
+// string matches the regular expression. It panics on an invalid pattern.
+func ErrMatch(pattern string) error {
+	re := regexp.MustCompile(pattern)
+	return errCheck{
+		desc: fmt.Sprintf("match %s", pattern),
+		match: func(actual error) (bool, string) {
+			if re.MatchString(actual.Error()) {
+				return true, ""
+			}
+			return false, fmt.Sprintf("%q does not match /%s/", actual.Error(), pattern)
+		},
+	}
+}
+
+// ErrFunc can be used with ExpectedErr to check the actual error with
+// a custom function. fn returns if the error is expected and a
+// description of the differences when it is not.
+func ErrFunc(fn func(error) (bool, string)) error {
+	return errCheck{
+		desc:  "ErrFunc",
+		match: fn,
+	}
+}
+
+// searched lists the chain of errors that was checked
+func searched(chain []error) string {
+	s := "searched:"
+	for _, e := range chain {
+		s += fmt.Sprintf("\n  %T: %v", e, e)
+	}
+	return s
+}
+
+// errChain returns err and all the errors it wraps depth first.
+// Both Unwrap() error and Unwrap() []error (errors.Join) are followed.
+func errChain(err error) []error {
+	if err == nil {
+		return nil
+	}
+	chain := []error{err}
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		chain = append(chain, errChain(x.Unwrap())...)
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			chain = append(chain, errChain(e)...)
+		}
+	}
+	return chain
+}
+
+// Result of a single case
+type Result[Out any] struct {
+	Name     string // name of the case
+	Input    any    // input passed to the test function
+	Success  bool
+	Skipped  bool
+	TimedOut bool
+	Message  string // formatted PASS, FAIL, PANIC or SKIP message
+
+	Output   Out           // value returned by the test function
+	Err      error         // error returned by the test function
+	Panic    any           // value recovered from a panic
+	Stack    string        // stack trace of the panic
+	Diff     string        // differences between the actual and expected values
+	Duration time.Duration // time spent in the test function
+
+	// heap allocations of the test function, only measured for cases
+	// with a MaxAllocs or MaxBytes budget
+	Allocs uint64
+	Bytes  uint64
+
 	panicCheck bool
+	panicked   bool // unexpected panic
 }
 
-func (r *result) pass(format string, args ...interface{}) {
+func (r *Result[Out]) pass(format string, args ...interface{}) {
 	r.Success = true
 	r.Message = fmt.Sprintf(format, args...)
 }
 
-func (r *result) fail(format string, args ...interface{}) {
+func (r *Result[Out]) fail(format string, args ...interface{}) {
 	r.Success = false
 	r.Message = fmt.Sprintf(format, args...)
 }
 
-func (r result) string() string {
+func (r *Result[Out]) skip(format string, args ...interface{}) {
+	r.Skipped = true
+	r.Message = fmt.Sprintf(format, args...)
+}
+
+// status of the result
+func (r Result[Out]) status() Status {
+	switch {
+	case r.Skipped:
+		return StatusSkip
+	case r.Success:
+		return StatusPass
+	case r.TimedOut:
+		return StatusTimeout
+	case r.panicked:
+		return StatusPanic
+	}
+	return StatusFail
+}
+
+func (r Result[Out]) string() string {
 	return fmt.Sprintf("{Success: %v, Message: %s, value: %v, err: %v, paniced: %v}",
-		r.Success, r.Message, r.value, r.err, r.panicCheck)
+		r.Success, r.Message, r.Output, r.Err, r.panicCheck)
 
 }
 
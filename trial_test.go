@@ -1,8 +1,14 @@
 package trial
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -35,7 +41,7 @@ func TestTrial_TestCase(t *testing.T) {
 	cases := map[string]struct {
 		trial     *Trial[Input, any]
 		Case      Case[Input, any]
-		expResult result
+		expResult Result[any]
 	}{
 		"1/1 - pass case": {
 			trial: New(divideFn, nil),
@@ -43,7 +49,7 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:    Args(1, 1),
 				Expected: 1,
 			},
-			expResult: result{Success: true, Message: `PASS: "1/1 - pass case"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "1/1 - pass case"`},
 		},
 		"1/0 - error check": {
 			trial: New(divideFn, nil),
@@ -51,14 +57,14 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:     Args(1, 0),
 				ShouldErr: true,
 			},
-			expResult: result{Success: true, Message: `PASS: "1/0 - error check"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "1/0 - error check"`},
 		},
 		"1/0 - unexpected error": {
 			trial: New(divideFn, nil),
 			Case: Case[Input, any]{
 				Input: Args(1, 0),
 			},
-			expResult: result{Success: false, Message: `FAIL: "1/0 - unexpected error" unexpected error 'divide by zero'`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "1/0 - unexpected error" unexpected error 'divide by zero'`},
 		},
 		"10/2 - unexpected result": {
 			trial: New(divideFn, nil),
@@ -66,7 +72,7 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:    Args(10, 2),
 				Expected: 10,
 			},
-			expResult: result{Success: false, Message: "FAIL: \"10/2 - unexpected result\""},
+			expResult: Result[any]{Success: false, Message: "FAIL: \"10/2 - unexpected result\""},
 		},
 		"parse time": {
 			trial: New(panicFn, nil),
@@ -74,7 +80,7 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:    Args("2018-01-02T00:00:00Z"),
 				Expected: "2018-01-02",
 			},
-			expResult: result{Success: true, Message: `PASS: "parse time"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "parse time"`},
 		},
 		"parse time with panic": {
 			trial: New(panicFn, nil),
@@ -82,14 +88,64 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:       Args("invalid"),
 				ShouldPanic: true,
 			},
-			expResult: result{Success: true, Message: `PASS: "parse time with panic"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "parse time with panic"`},
 		},
 		"parse time with unexpected panic": {
 			trial: New(panicFn, nil),
 			Case: Case[Input, any]{
 				Input: Args("invalid"),
 			},
-			expResult: result{Success: false, Message: `PANIC: "parse time with unexpected panic" parsing time "invalid" as "2006-01-02T15:04:05Z07:00": cannot parse "invalid" as "2006"`},
+			expResult: Result[any]{Success: false, Message: `PANIC: "parse time with unexpected panic" parsing time "invalid" as "2006-01-02T15:04:05Z07:00": cannot parse "invalid" as "2006"`},
+		},
+		"expected panic message": {
+			trial: New(panicFn, nil),
+			Case: Case[Input, any]{
+				Input:         Args("invalid"),
+				ExpectedPanic: `cannot parse "invalid"`,
+			},
+			expResult: Result[any]{Success: true, Message: `PASS: "expected panic message"`},
+		},
+		"expected panic error type": {
+			trial: New(panicFn, nil),
+			Case: Case[Input, any]{
+				Input:         Args("invalid"),
+				ExpectedPanic: ErrAs[*time.ParseError](),
+			},
+			expResult: Result[any]{Success: true, Message: `PASS: "expected panic error type"`},
+		},
+		"expected panic value": {
+			trial: New(func(Input) (interface{}, error) {
+				panic(testPanic{Code: 7})
+			}, nil),
+			Case: Case[Input, any]{
+				ExpectedPanic: testPanic{Code: 7},
+			},
+			expResult: Result[any]{Success: true, Message: `PASS: "expected panic value"`},
+		},
+		"panic value mismatch": {
+			trial: New(func(Input) (interface{}, error) {
+				panic(testPanic{Code: 7})
+			}, nil),
+			Case: Case[Input, any]{
+				ExpectedPanic: testPanic{Code: 8},
+			},
+			expResult: Result[any]{Success: false, Message: `FAIL: "panic value mismatch" panic "{7}" does not match expected "{8}"`},
+		},
+		"panic message mismatch": {
+			trial: New(panicFn, nil),
+			Case: Case[Input, any]{
+				Input:         Args("invalid"),
+				ExpectedPanic: "out of range",
+			},
+			expResult: Result[any]{Success: false, Message: `does not contain "out of range"`},
+		},
+		"expected panic value did not occur": {
+			trial: New(panicFn, nil),
+			Case: Case[Input, any]{
+				Input:         Args("2018-01-02T00:00:00Z"),
+				ExpectedPanic: "invalid",
+			},
+			expResult: Result[any]{Success: false, Message: `FAIL: "expected panic value did not occur" did not panic`},
 		},
 		"expected panic did not occur": {
 			trial: New(func(Input) (interface{}, error) {
@@ -98,7 +154,7 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ShouldPanic: true,
 			},
-			expResult: result{Success: false, Message: `FAIL: "expected panic did not occur" did not panic`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "expected panic did not occur" did not panic`},
 		},
 		"test should error but no error occurred": {
 			trial: New(func(Input) (interface{}, error) {
@@ -107,7 +163,7 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ShouldErr: true,
 			},
-			expResult: result{Success: false, Message: `FAIL: "test should error but no error occurred" should error`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "test should error but no error occurred" should error`},
 		},
 		"expected error string match": {
 			trial: New(func(Input) (interface{}, error) {
@@ -116,7 +172,7 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ExpectedErr: errors.New("test error"),
 			},
-			expResult: result{Success: true, Message: `PASS: "expected error string match"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "expected error string match"`},
 		},
 		"expected error string does not match": {
 			trial: New(divideFn, nil),
@@ -124,7 +180,7 @@ func TestTrial_TestCase(t *testing.T) {
 				Input:       Args(10, 0),
 				ExpectedErr: errors.New("test error"),
 			},
-			expResult: result{Success: false, Message: `FAIL: "expected error string does not match" error "divide by zero" does not match expected "test error"`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "expected error string does not match" error "divide by zero" does not match expected "test error"`},
 		},
 		"expected error of type testErr": {
 			trial: New(func(Input) (interface{}, error) {
@@ -133,7 +189,7 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ExpectedErr: ErrType(testErr{}),
 			},
-			expResult: result{Success: true, Message: `PASS: "expected error of type testErr"`},
+			expResult: Result[any]{Success: true, Message: `PASS: "expected error of type testErr"`},
 		},
 		"error type testErr with nil response": {
 			trial: New(func(Input) (interface{}, error) {
@@ -142,7 +198,7 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ExpectedErr: ErrType(testErr{}),
 			},
-			expResult: result{Success: false, Message: `FAIL: "error type testErr with nil response"`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "error type testErr with nil response"`},
 		},
 		"error type testErr with mismatch response": {
 			trial: New(func(Input) (interface{}, error) {
@@ -151,7 +207,90 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ExpectedErr: ErrType(testErr{}),
 			},
-			expResult: result{Success: false, Message: `FAIL: "error type testErr with mismatch response"`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "error type testErr with mismatch response"`},
+		},
+		"ErrIs wrapped error": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, fmt.Errorf("read: %w", io.EOF)
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrIs(io.EOF)},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrIs wrapped error"`},
+		},
+		"ErrIs joined errors": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, fmt.Errorf("close: %w", joinErr{errors.New("a"), io.EOF})
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrIs(io.EOF)},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrIs joined errors"`},
+		},
+		"ErrIs mismatch": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, fmt.Errorf("read: %w", io.ErrUnexpectedEOF)
+			}, nil),
+			Case: Case[Input, any]{ExpectedErr: ErrIs(io.EOF)},
+			expResult: Result[any]{Success: false, Message: `FAIL: "ErrIs mismatch" error "read: unexpected EOF" does not match expected "is EOF"
+searched:
+  *fmt.wrapError: read: unexpected EOF
+  *errors.errorString: unexpected EOF`},
+		},
+		"ErrAs wrapped error": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, fmt.Errorf("wrap: %w", testErr{})
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrAs[testErr]()},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrAs wrapped error"`},
+		},
+		"ErrAs joined pointer": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, joinErr{io.EOF, fmt.Errorf("wrap: %w", &os.PathError{Op: "open", Err: io.EOF})}
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrAs[*os.PathError]()},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrAs joined pointer"`},
+		},
+		"ErrAs mismatch": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, fmt.Errorf("wrap: %w", io.EOF)
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrAs[*os.PathError]()},
+			expResult: Result[any]{Success: false, Message: `does not match expected "as *fs.PathError"`},
+		},
+		"ErrMatch with variable id": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, errors.New("user 8a3f2c not found")
+			}, nil),
+			Case:      Case[Input, any]{ExpectedErr: ErrMatch(`^user [0-9a-f]+ not found$`)},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrMatch with variable id"`},
+		},
+		"ErrMatch mismatch": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, errors.New("user bob not found")
+			}, nil),
+			Case: Case[Input, any]{ExpectedErr: ErrMatch(`^user \d+`)},
+			expResult: Result[any]{Success: false, Message: `FAIL: "ErrMatch mismatch" error "user bob not found" does not match expected "match ^user \\d+"
+"user bob not found" does not match /^user \d+/`},
+		},
+		"ErrFunc custom check": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, &os.PathError{Op: "open", Path: "/tmp/a1b2", Err: os.ErrNotExist}
+			}, nil),
+			Case: Case[Input, any]{ExpectedErr: ErrFunc(func(err error) (bool, string) {
+				var pErr *os.PathError
+				if errors.As(err, &pErr) && pErr.Op == "open" {
+					return true, ""
+				}
+				return false, "expected open error"
+			})},
+			expResult: Result[any]{Success: true, Message: `PASS: "ErrFunc custom check"`},
+		},
+		"ErrFunc mismatch": {
+			trial: New(func(Input) (interface{}, error) {
+				return nil, errors.New("bad")
+			}, nil),
+			Case: Case[Input, any]{ExpectedErr: ErrFunc(func(err error) (bool, string) {
+				return false, "expected open error"
+			})},
+			expResult: Result[any]{Success: false, Message: `does not match expected "ErrFunc"
+expected open error`},
 		},
 		"timeout error": {
 			trial: New(func(Input) (interface{}, error) {
@@ -161,7 +300,53 @@ func TestTrial_TestCase(t *testing.T) {
 			Case: Case[Input, any]{
 				ExpectedErr: errors.New("timeout"),
 			},
-			expResult: result{Success: false, Message: `FAIL: "timeout error" timeout after 1ms`},
+			expResult: Result[any]{Success: false, Message: `FAIL: "timeout error" timeout after 1ms`},
+		},
+		"case timeout overrides trial": {
+			trial: New(func(Input) (interface{}, error) {
+				time.Sleep(20 * time.Millisecond)
+				return nil, nil
+			}, nil).Timeout(time.Millisecond),
+			Case:      Case[Input, any]{Timeout: time.Second},
+			expResult: Result[any]{Success: true, Message: `PASS: "case timeout overrides trial"`},
+		},
+		"case timeout without trial timeout": {
+			trial: New(func(Input) (interface{}, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			}, nil),
+			Case:      Case[Input, any]{Timeout: 2 * time.Millisecond},
+			expResult: Result[any]{Success: false, Message: `FAIL: "case timeout without trial timeout" timeout after 2ms`},
+		},
+		"skipped case": {
+			trial: New(func(Input) (interface{}, error) {
+				panic("should not run")
+			}, nil),
+			Case:      Case[Input, any]{Skip: "not ready"},
+			expResult: Result[any]{Success: false, Message: `SKIP: "skipped case" not ready`},
+		},
+		"context canceled on timeout": {
+			trial: NewCtx(func(ctx context.Context, _ Input) (interface{}, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}, nil).Timeout(time.Millisecond),
+			Case:      Case[Input, any]{},
+			expResult: Result[any]{Success: false, Message: `FAIL: "context canceled on timeout" timeout after 1ms`},
+		},
+		"context ignored on timeout": {
+			trial: NewCtx(func(context.Context, Input) (interface{}, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			}, nil).Timeout(time.Millisecond).GracePeriod(10 * time.Millisecond),
+			Case:      Case[Input, any]{},
+			expResult: Result[any]{Success: false, Message: `did not return within 10ms of cancel (leaked)`},
+		},
+		"context aware pass": {
+			trial: NewCtx(func(ctx context.Context, in Input) (interface{}, error) {
+				return in.Int(), ctx.Err()
+			}, nil).Timeout(time.Second),
+			Case:      Case[Input, any]{Input: Args(4), Expected: 4},
+			expResult: Result[any]{Success: true, Message: `PASS: "context aware pass"`},
 		},
 	}
 	for msg, test := range cases {
@@ -174,12 +359,356 @@ func TestTrial_TestCase(t *testing.T) {
 	}
 }
 
+func TestTrial_Parallel(t *testing.T) {
+	var mu sync.Mutex
+	var active, peak int
+	fn := func(i int) (int, error) {
+		mu.Lock()
+		active++
+		if active > peak {
+			peak = active
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return i, nil
+	}
+	cases := Cases[int, int]{}
+	for i := 0; i < 8; i++ {
+		cases[strconv.Itoa(i)] = Case[int, int]{Input: i, Expected: i}
+	}
+
+	// Test: results are returned in name order and limited by the workers
+	tr := New(fn, cases).Workers(3)
+	names := tr.names()
+	for i, r := range tr.run(names) {
+		if !r.Success || r.Output != cases[names[i]].Input {
+			t.Errorf("FAIL: %q out of order %v", names[i], r.string())
+		}
+	}
+	if peak < 2 || peak > 3 {
+		t.Errorf("FAIL: Test expected 2-3 concurrent cases got %d", peak)
+	}
+
+	// SubTest: parallel subtests complete before the parent returns
+	// the go test -parallel flag may lower the concurrency further
+	peak = 0
+	t.Run("SubTest", func(t *testing.T) {
+		New(fn, cases).Workers(2).SubTest(t)
+	})
+	if peak < 1 || peak > 2 {
+		t.Errorf("FAIL: SubTest expected 1-2 concurrent cases got %d", peak)
+	}
+}
+
+func TestTrial_Hooks(t *testing.T) {
+	var events []string
+	fn := func(i int) (int, error) {
+		switch i {
+		case -1:
+			panic("negative")
+		case -2:
+			time.Sleep(time.Second)
+		}
+		return i, nil
+	}
+	cases := Cases[int, int]{
+		"double": {Input: 2, Expected: 4},
+		"panic":  {Input: -1, ShouldPanic: true},
+		"timeout": {
+			Input:   -2,
+			Timeout: time.Millisecond,
+		},
+	}
+	tr := New(fn, cases).
+		BeforeAll(func() { events = append(events, "before all") }).
+		AfterAll(func() { events = append(events, "after all") }).
+		BeforeEach(func(name string, in *int) {
+			events = append(events, "before "+name)
+			if *in > 0 {
+				*in *= 2
+			}
+		}).
+		AfterEach(func(name string, r Result[int]) {
+			events = append(events, "after "+name+" "+strconv.FormatBool(r.Success))
+		})
+	results := tr.run(tr.names())
+	if !results[0].Success {
+		t.Errorf("FAIL: BeforeEach should modify input %v", results[0].string())
+	}
+	exp := []string{
+		"before double", "after double true",
+		"before panic", "after panic true",
+		"before timeout", "after timeout false",
+	}
+	if eq, diff := Equal(events, exp); !eq {
+		t.Errorf("FAIL: each hooks %s", diff)
+	}
+
+	events = events[:0]
+	delete(cases, "timeout")
+	t.Run("SubTest", func(t *testing.T) {
+		tr.SubTest(t)
+	})
+	exp = []string{
+		"before all",
+		"before double", "after double true",
+		"before panic", "after panic true",
+		"after all",
+	}
+	if eq, diff := Equal(events, exp); !eq {
+		t.Errorf("FAIL: all hooks %s", diff)
+	}
+}
+
+func TestTrial_Order(t *testing.T) {
+	fn := func(i int) (int, error) { return i, nil }
+	cases := Cases[int, int]{"c": {}, "a": {}, "d": {}, "b": {}, "e": {}}
+
+	if eq, diff := Equal(New(fn, cases).names(), []string{"a", "b", "c", "d", "e"}); !eq {
+		t.Errorf("FAIL: sorted %s", diff)
+	}
+
+	ordered := (&OrderedCases[int, int]{}).
+		Add("c", Case[int, int]{}).
+		Add("a", Case[int, int]{}).
+		Add("b", Case[int, int]{}).
+		Add("a", Case[int, int]{Input: 1})
+	tr := New(fn, cases).Ordered(ordered)
+	if eq, diff := Equal(tr.names(), []string{"c", "a", "b"}); !eq {
+		t.Errorf("FAIL: ordered %s", diff)
+	}
+	if tr.cases["a"].Input != 1 {
+		t.Errorf("FAIL: ordered case should be replaced")
+	}
+
+	s1 := New(fn, cases).Shuffle(42).names()
+	s2 := New(fn, cases).Shuffle(42).names()
+	if eq, diff := Equal(s1, s2); !eq {
+		t.Errorf("FAIL: same seed should give the same order %s", diff)
+	}
+	if eq, diff := Contains(s1, []string{"a", "b", "c", "d", "e"}); !eq || len(s1) != 5 {
+		t.Errorf("FAIL: shuffle should keep all cases %s", diff)
+	}
+}
+
+func TestTrial_Run(t *testing.T) {
+	fn := func(i int) (int, error) {
+		switch i {
+		case 0:
+			return 0, errors.New("zero")
+		case -1:
+			panic("negative")
+		}
+		return i * 2, nil
+	}
+	results := New(fn, Cases[int, int]{
+		"double": {Input: 2, Expected: 4},
+		"diff":   {Input: 3, Expected: 5},
+		"error":  {Input: 0, ShouldErr: true},
+		"panic":  {Input: -1},
+		"skip":   {Input: 1, Skip: "later"},
+	}).Run()
+
+	type summary struct {
+		Name    string
+		Input   any
+		Output  int
+		Success bool
+		Skipped bool
+		Err     bool
+		Panic   any
+		Diff    bool
+	}
+	actual := make([]summary, len(results))
+	for i, r := range results {
+		actual[i] = summary{
+			Name:    r.Name,
+			Input:   r.Input,
+			Output:  r.Output,
+			Success: r.Success,
+			Skipped: r.Skipped,
+			Err:     r.Err != nil,
+			Panic:   r.Panic,
+			Diff:    r.Diff != "",
+		}
+		if r.Panic != nil && !strings.Contains(r.Stack, "trial_test.go") {
+			t.Errorf("FAIL: %q missing panic stack %s", r.Name, r.Stack)
+		}
+	}
+	exp := []summary{
+		{Name: "diff", Input: 3, Output: 6, Diff: true},
+		{Name: "double", Input: 2, Output: 4, Success: true},
+		{Name: "error", Input: 0, Success: true, Err: true},
+		{Name: "panic", Input: -1, Panic: "negative"},
+		{Name: "skip", Input: 1, Skipped: true},
+	}
+	if eq, diff := Equal(actual, exp); !eq {
+		t.Errorf("FAIL: %s", diff)
+	}
+}
+
+// fakeTB records the messages logged to a test
+type fakeTB struct {
+	testing.TB
+	logs []string
+	errs []string
+}
+
+func (f *fakeTB) Helper()                 {}
+func (f *fakeTB) Name() string            { return "fake" }
+func (f *fakeTB) Log(args ...any)         { f.logs = append(f.logs, fmt.Sprint(args...)) }
+func (f *fakeTB) Logf(s string, a ...any) { f.logs = append(f.logs, fmt.Sprintf(s, a...)) }
+func (f *fakeTB) Error(args ...any)       { f.errs = append(f.errs, fmt.Sprint(args...)) }
+
+func TestTrial_Only(t *testing.T) {
+	fn := func(i int) (int, error) { return i, nil }
+	cases := Cases[int, int]{
+		"focus": {Input: 1, Expected: 1, Only: true},
+		"other": {Input: 2, Expected: 3},
+		"skip":  {Input: 3, Skip: "broken", Only: true},
+	}
+
+	t.Setenv("CI", "")
+	tb := &fakeTB{}
+	New(fn, cases).Test(tb)
+	exp := []string{
+		"WARNING: only running cases marked Only",
+		`PASS: "focus"`,
+		`SKIP: "other" not marked Only`,
+		`SKIP: "skip" broken`,
+	}
+	if eq, diff := Equal(tb.logs, exp); !eq || len(tb.errs) != 0 {
+		t.Errorf("FAIL: only %s %v", diff, tb.errs)
+	}
+
+	t.Setenv("CI", "true")
+	tb = &fakeTB{}
+	New(fn, cases).Test(tb)
+	if eq, diff := Equal(tb.errs, []string{"FAIL: cases marked Only are not allowed in CI"}); !eq {
+		t.Errorf("FAIL: only in CI %s", diff)
+	}
+}
+
 type testErr struct{}
 
+type testPanic struct{ Code int }
+
+// joinErr wraps multiple errors like errors.Join (go1.20)
+type joinErr []error
+
+func (e joinErr) Error() string   { return fmt.Sprint([]error(e)) }
+func (e joinErr) Unwrap() []error { return e }
+
 func (e testErr) Error() string {
 	return ""
 }
 
+func TestTrial_Budgets(t *testing.T) {
+	var sink []int
+	fn := func(n int) (int, error) {
+		if n > 0 {
+			sink = make([]int, n)
+		}
+		if n < 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return n, nil
+	}
+	cases := Cases[Case[int, int], string]{
+		"no allocs": {
+			Input:    Case[int, int]{Input: 0, MaxAllocs: NoAllocs, MaxBytes: NoAllocs},
+			Expected: `PASS: "test"`,
+		},
+		"within budget": {
+			Input:    Case[int, int]{Input: 10, Expected: 10, MaxAllocs: 1, MaxBytes: 1024},
+			Expected: `PASS: "test"`,
+		},
+		"too many allocs": {
+			Input:    Case[int, int]{Input: 10, Expected: 10, MaxAllocs: NoAllocs},
+			Expected: `FAIL: "test" 1 allocs exceeds max of 0`,
+		},
+		"too many bytes": {
+			Input:    Case[int, int]{Input: 128, Expected: 128, MaxBytes: 512},
+			Expected: `FAIL: "test" 1024 bytes allocated exceeds max of 512`,
+		},
+		"allocs and duration": {
+			// measuring allocations isn't counted against MaxDuration
+			Input:    Case[int, int]{Input: 10, Expected: 10, MaxAllocs: 1, MaxDuration: 5 * time.Millisecond},
+			Expected: `PASS: "test"`,
+		},
+		"too slow": {
+			Input:    Case[int, int]{Input: -1, Expected: -1, MaxDuration: time.Millisecond},
+			Expected: `exceeds max duration of 1ms`,
+		},
+		"wrong output first": {
+			Input:    Case[int, int]{Input: 10, Expected: 1, MaxAllocs: NoAllocs},
+			Expected: "FAIL: \"test\" \n",
+		},
+	}
+	New(func(c Case[int, int]) (string, error) {
+		r := New(fn, nil).testCase("test", c)
+		return r.Message, nil
+	}, cases).Comparer(Contains).SubTest(t)
+	_ = sink
+}
+
+func TestTrial_BudgetTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	fn := func(block bool) (bool, error) {
+		if block {
+			<-stop
+		}
+		return block, nil
+	}
+	tr := New(fn, nil).Timeout(10 * time.Millisecond)
+	r := tr.testCase("blocked", Case[bool, bool]{Input: true, MaxAllocs: 10})
+	if !r.TimedOut {
+		t.Fatalf("FAIL: expected timeout %s", r.Message)
+	}
+
+	// the blocked call is still running, later measured cases must not wait for it
+	done := make(chan Result[bool])
+	go func() { done <- tr.testCase("next", Case[bool, bool]{Input: false, MaxAllocs: 10}) }()
+	select {
+	case r := <-done:
+		if !r.Success {
+			t.Errorf("FAIL: %s", r.Message)
+		}
+	case <-time.After(time.Second):
+		t.Error("FAIL: measured case blocked by a timed out case")
+	}
+}
+
+func TestTrial_DetectLeaks(t *testing.T) {
+	defer func(d time.Duration) { leakSettle = d }(leakSettle)
+	leakSettle = 50 * time.Millisecond
+
+	stop := make(chan struct{})
+	defer close(stop)
+	fn := func(s string) (string, error) {
+		switch s {
+		case "leak":
+			go func() { <-stop }()
+		case "settle":
+			go func() { time.Sleep(10 * time.Millisecond) }()
+		}
+		return s, nil
+	}
+	cases := Cases[string, string]{
+		"none":   {Input: "none", Expected: `PASS: "test"`},
+		"settle": {Input: "settle", Expected: `PASS: "test"`},
+		"leak":   {Input: "leak", Expected: `FAIL: "test" leaked 1 goroutine(s)` + "\ngoroutine "},
+	}
+	New(func(s string) (string, error) {
+		r := New(fn, nil).DetectLeaks().testCase("test", Case[string, string]{Input: s, Expected: s})
+		return r.Message, nil
+	}, cases).Comparer(Contains).SubTest(t)
+}
+
 func TestInput(t *testing.T) {
 	type tester struct {
 		shouldPanic bool
@@ -273,7 +802,7 @@ func TestInput(t *testing.T) {
 		"[]string": {
 			fn: func() interface{} {
 				in := newInput([]string{"ab", "cd", "ef", "g"})
-				in.Slice(0).String()
+				_ = in.Slice(0).String()
 				return in.Slice(2).String()
 			},
 			expected: "ef",